@@ -0,0 +1,299 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tag name to declare a single character alias of a command-line flag
+const shortTagName = "short"
+
+// flagSpec describes the command-line flag of a struct field.
+type flagSpec struct {
+	// key is the name the value is stored under, the arg tag or field name.
+	key    string
+	short  string
+	isBool bool
+}
+
+// flagSpecs indexes the flags of a configuration struct by long and short name
+// and its subcommands by name.
+type flagSpecs struct {
+	long     map[string]*flagSpec
+	short    map[string]*flagSpec
+	commands map[string]int
+	// positionals maps a position to the index of the field bound to it,
+	// rest is the index of the field holding the remaining ones or -1.
+	positionals map[int]int
+	rest        int
+}
+
+// parsedArgs is the result of tokenizing the command line once per load.
+type parsedArgs struct {
+	values     map[string]string
+	positional []string
+	unknown    []string
+	help       bool
+	// command is the selected subcommand, rest holds the arguments after it.
+	command string
+	rest    []string
+}
+
+func (a *parsedArgs) getFromArg(p reflect.StructField, key string) (string, string, error) {
+	return a.values[key], key, nil
+}
+
+// getFlagSpecs collects the flags declared by the fields of typ. Aliases of
+// a field are flags of their own so their values can be told apart.
+func getFlagSpecs(typ reflect.Type) (flagSpecs, error) {
+	specs := flagSpecs{
+		long:        map[string]*flagSpec{},
+		short:       map[string]*flagSpec{},
+		commands:    map[string]int{},
+		positionals: map[int]int{},
+		rest:        -1,
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		if p.Anonymous || len(p.PkgPath) > 0 {
+			continue
+		}
+		if isCommand(p) {
+			if err := checkCommand(p); err != nil {
+				return specs, err
+			}
+			specs.commands[p.Tag.Get(cmdTagName)] = i
+			continue
+		}
+		if isPositional(p) {
+			if err := specs.addPositional(typ, i); err != nil {
+				return specs, err
+			}
+			continue
+		}
+		if !isScalar(p.Type) {
+			// nested structs, slices and maps can not be given as a flag
+			continue
+		}
+
+		isBool := p.Type.Kind() == reflect.Bool
+		key := getKey(p, argTagName)
+		spec := &flagSpec{key: key, short: p.Tag.Get(shortTagName), isBool: isBool}
+		specs.long[key] = spec
+		if len(spec.short) > 1 || spec.short == "-" {
+			return specs, fmt.Errorf("short flag of field %s should be a single character: %q", p.Name, spec.short)
+		}
+		if len(spec.short) > 0 {
+			specs.short[spec.short] = spec
+		}
+		for _, alias := range getAliases(p) {
+			specs.long[alias] = &flagSpec{key: alias, isBool: isBool}
+		}
+	}
+	return specs, nil
+}
+
+// parseArgs tokenizes args the way GNU getopt_long does. Long flags take
+// their value after a "=" or as the next argument, short flags can be
+// combined ("-abc") and take their value from the rest of the argument or
+// the next one. Boolean flags can be negated with a "--no-" prefix and
+// everything after "--" is positional. Parsing stops at the name of a
+// subcommand given as first positional argument.
+func parseArgs(args []string, specs flagSpecs) (*parsedArgs, error) {
+	parsed := &parsedArgs{values: map[string]string{}}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			parsed.positional = append(parsed.positional, args[i+1:]...)
+			return parsed, nil
+
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := cut(arg[2:], "=")
+			spec := specs.long[name]
+			if spec == nil && name == helpFlag && !hasValue {
+				parsed.help = true
+				continue
+			}
+			if spec == nil && strings.HasPrefix(name, "no-") && !hasValue {
+				if negated := specs.long[name[3:]]; negated != nil && negated.isBool {
+					parsed.values[negated.key] = "false"
+					continue
+				}
+			}
+			if spec == nil {
+				parsed.unknown = append(parsed.unknown, "--"+name)
+				continue
+			}
+			if !hasValue && spec.isBool {
+				// like -v, a boolean flag only takes a value after a "="
+				value = "true"
+			} else if !hasValue {
+				var ok bool
+				if value, ok = takeValue(args, &i); !ok {
+					return nil, fmt.Errorf("flag needs an argument: --%s", name)
+				}
+			}
+			parsed.values[spec.key] = value
+
+		case strings.HasPrefix(arg, "-") && len(arg) > 1 && !isNumber(arg):
+			for j := 1; j < len(arg); j++ {
+				name := arg[j : j+1]
+				spec := specs.short[name]
+				if spec == nil && name == helpShortFlag {
+					parsed.help = true
+					continue
+				}
+				if spec == nil {
+					// the rest of the argument can't be told apart from a value
+					parsed.unknown = append(parsed.unknown, "-"+name)
+					break
+				}
+				if spec.isBool {
+					parsed.values[spec.key] = "true"
+					continue
+				}
+				value := strings.TrimPrefix(arg[j+1:], "=")
+				if len(value) == 0 {
+					var ok bool
+					if value, ok = takeValue(args, &i); !ok {
+						return nil, fmt.Errorf("flag needs an argument: -%s", name)
+					}
+				}
+				parsed.values[spec.key] = value
+				break
+			}
+
+		default:
+			if _, ok := specs.commands[arg]; ok && len(parsed.positional) == 0 {
+				parsed.command = arg
+				parsed.rest = args[i+1:]
+				return parsed, nil
+			}
+			parsed.positional = append(parsed.positional, arg)
+		}
+	}
+	return parsed, nil
+}
+
+// takeValue consumes the argument following args[*i] as the value of a
+// flag, unless it looks like another flag. Negative numbers are values.
+func takeValue(args []string, i *int) (string, bool) {
+	if *i+1 < len(args) {
+		next := args[*i+1]
+		if !strings.HasPrefix(next, "-") || next == "-" || isNumber(next) {
+			*i++
+			return next, true
+		}
+	}
+	return "", false
+}
+
+func isNumber(arg string) bool {
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
+
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// UnknownFlagError is returned by Load when the command line holds flags the
+// configuration struct does not declare.
+type UnknownFlagError struct {
+	// Flags lists the unknown flags as they were given.
+	Flags []string
+	// Suggestions maps an unknown flag to the declared flags closest to it.
+	Suggestions map[string][]string
+}
+
+func (e *UnknownFlagError) Error() string {
+	messages := make([]string, 0, len(e.Flags))
+	for _, flag := range e.Flags {
+		message := "unknown flag " + flag
+		if suggestions := e.Suggestions[flag]; len(suggestions) > 0 {
+			message += ", did you mean " + strings.Join(suggestions, " or ") + "?"
+		}
+		messages = append(messages, message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func newUnknownFlagError(unknown []string, specs flagSpecs) *UnknownFlagError {
+	e := &UnknownFlagError{Flags: unknown, Suggestions: map[string][]string{}}
+	for _, flag := range unknown {
+		if !strings.HasPrefix(flag, "--") {
+			continue
+		}
+		if suggestions := suggestFlags(flag[2:], specs); len(suggestions) > 0 {
+			e.Suggestions[flag] = suggestions
+		}
+	}
+	return e
+}
+
+// suggestFlags returns the long flags closest to name by edit distance,
+// ignoring those too far away to be a typo.
+func suggestFlags(name string, specs flagSpecs) []string {
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	var suggestions []string
+	best := maxDistance + 1
+	for long := range specs.long {
+		distance := levenshtein(strings.ToLower(name), strings.ToLower(long))
+		if distance > maxDistance {
+			continue
+		}
+		if distance < best {
+			best = distance
+			suggestions = nil
+		}
+		if distance == best {
+			suggestions = append(suggestions, "--"+long)
+		}
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
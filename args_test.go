@@ -0,0 +1,223 @@
+package gonfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type argsConf struct {
+	Port    int    `short:"p"`
+	Offset  int    `arg:"offset"`
+	Verbose bool   `short:"v"`
+	Debug   bool   `short:"d"`
+	Color   bool   `arg:"color"`
+	Name    string `short:"n"`
+}
+
+func parseArgsConf(args []string, t *testing.T) *parsedArgs {
+	specs, err := getFlagSpecs(reflect.TypeOf(argsConf{}))
+	if err != nil {
+		t.Fatal("getFlagSpecs unexpected error occured", err)
+	}
+	parsed, err := parseArgs(args, specs)
+	if err != nil {
+		t.Fatal("parseArgs unexpected error occured", err)
+	}
+	return parsed
+}
+
+func Test_parseArgs_should_parse_short_flags(t *testing.T) {
+	parsed := parseArgsConf([]string{"-p", "80", "-nfoo"}, t)
+
+	if parsed.values["Port"] != "80" {
+		t.Error("Port should be 80 but is : ", parsed.values["Port"])
+	}
+	if parsed.values["Name"] != "foo" {
+		t.Error("Name should be foo but is : ", parsed.values["Name"])
+	}
+}
+
+func Test_parseArgs_should_parse_combined_short_flags(t *testing.T) {
+	parsed := parseArgsConf([]string{"-vdp8080"}, t)
+
+	if parsed.values["Verbose"] != "true" {
+		t.Error("Verbose should be true but is : ", parsed.values["Verbose"])
+	}
+	if parsed.values["Debug"] != "true" {
+		t.Error("Debug should be true but is : ", parsed.values["Debug"])
+	}
+	if parsed.values["Port"] != "8080" {
+		t.Error("Port should be 8080 but is : ", parsed.values["Port"])
+	}
+}
+
+func Test_parseArgs_should_stop_at_terminator(t *testing.T) {
+	parsed := parseArgsConf([]string{"--Port=1", "file1", "--", "--Port=2", "-v"}, t)
+
+	if parsed.values["Port"] != "1" {
+		t.Error("Port should be 1 but is : ", parsed.values["Port"])
+	}
+	if _, ok := parsed.values["Verbose"]; ok {
+		t.Error("Verbose should not be set")
+	}
+	if !reflect.DeepEqual(parsed.positional, []string{"file1", "--Port=2", "-v"}) {
+		t.Error("unexpected positional arguments", parsed.positional)
+	}
+}
+
+func Test_parseArgs_should_negate_bool_flags(t *testing.T) {
+	parsed := parseArgsConf([]string{"--no-color", "--no-Port"}, t)
+
+	if parsed.values["color"] != "false" {
+		t.Error("color should be false but is : ", parsed.values["color"])
+	}
+	if _, ok := parsed.values["Port"]; ok {
+		t.Error("only bool flags can be negated")
+	}
+}
+
+func Test_parseArgs_should_take_negative_numbers_as_values(t *testing.T) {
+	parsed := parseArgsConf([]string{"--offset", "-5", "-p", "-1", "-3"}, t)
+
+	if parsed.values["offset"] != "-5" {
+		t.Error("offset should be -5 but is : ", parsed.values["offset"])
+	}
+	if parsed.values["Port"] != "-1" {
+		t.Error("Port should be -1 but is : ", parsed.values["Port"])
+	}
+	if !reflect.DeepEqual(parsed.positional, []string{"-3"}) {
+		t.Error("unexpected positional arguments", parsed.positional)
+	}
+}
+
+func Test_parseArgs_should_only_take_bool_values_after_equal_sign(t *testing.T) {
+	parsed := parseArgsConf([]string{"--Verbose", "false", "--Debug=false", "-v", "true", "file"}, t)
+
+	if parsed.values["Verbose"] != "true" {
+		t.Error("Verbose should be true but is : ", parsed.values["Verbose"])
+	}
+	if parsed.values["Debug"] != "false" {
+		t.Error("Debug should be false but is : ", parsed.values["Debug"])
+	}
+	if !reflect.DeepEqual(parsed.positional, []string{"false", "true", "file"}) {
+		t.Error("unexpected positional arguments", parsed.positional)
+	}
+}
+
+func Test_parseArgs_should_fail_on_missing_value(t *testing.T) {
+	specs, _ := getFlagSpecs(reflect.TypeOf(argsConf{}))
+
+	if _, err := parseArgs([]string{"--Name", "--Verbose"}, specs); err == nil {
+		t.Error("parseArgs should fail if --Name has no value")
+	}
+	if _, err := parseArgs([]string{"-n"}, specs); err == nil {
+		t.Error("parseArgs should fail if -n has no value")
+	}
+}
+
+func Test_getFlagSpecs_should_reject_long_short_flags(t *testing.T) {
+	type Conf struct {
+		Port int `short:"port"`
+	}
+	if _, err := getFlagSpecs(reflect.TypeOf(Conf{})); err == nil {
+		t.Error("getFlagSpecs should reject short flags longer than one character")
+	}
+}
+
+func Test_getFlagSpecs_should_skip_nested_fields(t *testing.T) {
+	type Conf struct {
+		Port     int
+		Database struct{ Host string }
+		Hosts    []string
+		Labels   map[string]string
+	}
+	specs, err := getFlagSpecs(reflect.TypeOf(Conf{}))
+	if err != nil {
+		t.Fatal("getFlagSpecs unexpected error occured", err)
+	}
+	if len(specs.long) != 1 || specs.long["Port"] == nil {
+		t.Error("only Port should be a flag", specs.long)
+	}
+}
+
+func Test_Load_should_fail_on_invalid_flag_values(t *testing.T) {
+	type Conf struct {
+		Port     int
+		Password int `secret:"true"`
+	}
+
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: []string{"cmd", "--Port=abc"}})
+	if err == nil || err.Error() != `invalid value "abc" for flag --Port: strconv.ParseInt: parsing "abc": invalid syntax` {
+		t.Error("Load should fail on an invalid flag value", err)
+	}
+
+	_, err = Load(&conf, Options{Args: []string{"cmd", "--Password=hunter2"}})
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Error("Load should fail without revealing the secret", err)
+	}
+}
+
+func Test_Load_should_return_positional_arguments(t *testing.T) {
+	args := []string{"cmd", "-vp", "80", "input.txt", "output.txt"}
+
+	conf := argsConf{}
+	report, err := Load(&conf, Options{Args: args})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.Port != 80 {
+		t.Error("Port should be 80", conf.Port)
+	}
+	if !conf.Verbose {
+		t.Error("Verbose should be true", conf.Verbose)
+	}
+	if !reflect.DeepEqual(report.Args, []string{"input.txt", "output.txt"}) {
+		t.Error("unexpected positional arguments", report.Args)
+	}
+}
+
+func Test_Load_should_report_unknown_flags(t *testing.T) {
+	args := []string{"cmd", "--prot=80", "-x", "--Verbose"}
+
+	conf := argsConf{}
+	_, err := Load(&conf, Options{Args: args})
+
+	unknown, ok := err.(*UnknownFlagError)
+	if !ok {
+		t.Fatal("Load should fail with an UnknownFlagError", err)
+	}
+	if !reflect.DeepEqual(unknown.Flags, []string{"--prot", "-x"}) {
+		t.Error("unexpected unknown flags", unknown.Flags)
+	}
+	if !reflect.DeepEqual(unknown.Suggestions["--prot"], []string{"--Port"}) {
+		t.Error("--Port should be suggested for --prot", unknown.Suggestions)
+	}
+	if err.Error() != "unknown flag --prot, did you mean --Port?; unknown flag -x" {
+		t.Error("unexpected error message", err)
+	}
+}
+
+func Test_Load_should_allow_unknown_flags(t *testing.T) {
+	args := []string{"cmd", "--typo=1", "--Port=80"}
+
+	conf := argsConf{}
+	_, err := Load(&conf, Options{Args: args, AllowUnknownFlags: true})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.Port != 80 {
+		t.Error("Port should be 80", conf.Port)
+	}
+}
+
+func Test_suggestFlags_should_ignore_distant_flags(t *testing.T) {
+	specs, _ := getFlagSpecs(reflect.TypeOf(argsConf{}))
+
+	if suggestions := suggestFlags("timeout", specs); len(suggestions) != 0 {
+		t.Error("there should be no suggestion for timeout", suggestions)
+	}
+}
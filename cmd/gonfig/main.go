@@ -0,0 +1,297 @@
+// Command gonfig checks configuration files against the JSON Schema written
+// by gonfig.GenerateSchema, so they can be validated in CI without starting
+// the service reading them.
+//
+//	gonfig validate --schema config.schema.json config.yaml
+//	gonfig print --schema config.schema.json --env-file prod.env config.yaml
+//	gonfig explain --schema config.schema.json --field Database config.yaml
+//
+// The environment variables are only read from --env-file, so the results
+// don't depend on the machine running the checks.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/B4dT0bi/gonfig"
+	"github.com/ghodss/yaml"
+)
+
+// checkConfig selects the configuration file to check and the sources of the
+// values merged with it.
+type checkConfig struct {
+	Schema     string `arg:"schema" short:"s" env:"GONFIG_SCHEMA" description:"JSON Schema of the configuration, required"`
+	EnvFile    string `arg:"env-file" short:"e" env:"GONFIG_ENV_FILE" description:"file setting the environment variables, one KEY=VALUE per line"`
+	Key        string `arg:"key" short:"k" env:"GONFIG_KEY_FILE" description:"key file decrypting the ENC[...] values"`
+	Format     string `arg:"format" short:"f" env:"GONFIG_FORMAT" default:"yaml" description:"format printed by print, yaml or json"`
+	NonDefault bool   `arg:"non-default" env:"GONFIG_NON_DEFAULT" description:"only print the values differing from their defaults"`
+	Field      string `arg:"field" env:"GONFIG_FIELD" description:"only explain this field and the fields below it"`
+	Config     string `pos:"0" env:"GONFIG_CONFIG" description:"configuration file or URL"`
+}
+
+type cliConfig struct {
+	Validate *checkConfig `cmd:"validate" description:"check a configuration file against the schema"`
+	Print    *checkConfig `cmd:"print" description:"print the merged configuration"`
+	Explain  *checkConfig `cmd:"explain" description:"show where every value comes from"`
+}
+
+func main() {
+	os.Exit(run(os.Args, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code: 0 on
+// success, 1 if the configuration is invalid and 2 if the command line is.
+func run(args []string, stdout, stderr io.Writer) int {
+	cli := cliConfig{}
+	_, err := gonfig.Load(&cli, gonfig.Options{Args: args, HelpOutput: stdout})
+	if errors.Is(err, gonfig.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	var c *checkConfig
+	var command func(c *checkConfig, stdout, stderr io.Writer) int
+	switch {
+	case cli.Validate != nil:
+		c, command = cli.Validate, validate
+	case cli.Print != nil:
+		c, command = cli.Print, printConfiguration
+	case cli.Explain != nil:
+		c, command = cli.Explain, explain
+	default:
+		gonfig.Load(&cli, gonfig.Options{Args: []string{args[0], "--help"}, HelpOutput: stderr})
+		return 2
+	}
+	if len(c.Schema) == 0 {
+		fmt.Fprintln(stderr, "no schema given, set --schema or GONFIG_SCHEMA")
+		return 2
+	}
+	return command(c, stdout, stderr)
+}
+
+func validate(c *checkConfig, stdout, stderr io.Writer) int {
+	schema, configuration, report, err := load(c, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	problems := unknownKeys(c.Config, schema)
+	problems = append(problems, missingFields(reflect.TypeOf(configuration).Elem(), "", report)...)
+	for _, d := range report.Deprecations {
+		problems = append(problems, d.String())
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(stderr, problem)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s is valid\n", c.Config)
+	return 0
+}
+
+func printConfiguration(c *checkConfig, stdout, stderr io.Writer) int {
+	_, configuration, _, err := load(c, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	data, err := gonfig.MarshalOptions{OmitDefaults: c.NonDefault}.Marshal(configuration, gonfig.Format(c.Format))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	stdout.Write(data)
+	return 0
+}
+
+func explain(c *checkConfig, stdout, stderr io.Writer) int {
+	_, _, report, err := load(c, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	for _, p := range report.Provenance {
+		if len(c.Field) == 0 || p.Path == c.Field || strings.HasPrefix(p.Path, c.Field+".") {
+			fmt.Fprintln(stdout, p)
+		}
+	}
+	return 0
+}
+
+// load reads the schema and the configuration file merged with the default
+// values and the environment variables of the env file.
+func load(c *checkConfig, stderr io.Writer) (*gonfig.Schema, interface{}, *gonfig.Report, error) {
+	if len(c.Config) == 0 {
+		return nil, nil, nil, errors.New("no configuration file given")
+	}
+	data, err := os.ReadFile(c.Schema)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// defaults are read as json.Number so large integers are kept as is
+	schema := &gonfig.Schema{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(schema); err != nil {
+		return nil, nil, nil, fmt.Errorf("could not read schema %s: %v", c.Schema, err)
+	}
+	typ, err := structOf(schema)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not read schema %s: %v", c.Schema, err)
+	}
+
+	env := map[string]string{}
+	if len(c.EnvFile) > 0 {
+		if env, err = readEnvFile(c.EnvFile); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	opts := gonfig.Options{
+		Filename:    c.Config,
+		RequireFile: true,
+		Args:        []string{"gonfig"},
+		LookupEnv:   gonfig.MapEnv(env),
+		Logger:      stderrLogger{stderr},
+	}
+	if len(c.Key) > 0 {
+		if opts.Decrypter, err = gonfig.NewKeyFileDecrypter(c.Key); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	configuration := reflect.New(typ).Interface()
+	report, err := gonfig.Load(configuration, opts)
+	return schema, configuration, report, err
+}
+
+type stderrLogger struct {
+	w io.Writer
+}
+
+func (l stderrLogger) Printf(format string, v ...interface{}) {
+	fmt.Fprintf(l.w, format+"\n", v...)
+}
+
+// readEnvFile reads KEY=VALUE lines, skipping blank lines and comments.
+// Values can be quoted, and lines can start with export like in a shell.
+func readEnvFile(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	env := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", filename, number)
+		}
+		key := strings.TrimSpace(strings.TrimPrefix(line[:i], "export "))
+		value := strings.TrimSpace(line[i+1:])
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			if value, err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", filename, number, err)
+			}
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		env[key] = value
+	}
+	return env, scanner.Err()
+}
+
+// unknownKeys lists the keys of the configuration file the schema doesn't
+// describe. Files given by URL are not checked.
+func unknownKeys(filename string, schema *gonfig.Schema) []string {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	var document map[string]interface{}
+	if yaml.Unmarshal(data, &document) != nil {
+		return nil
+	}
+	var problems []string
+	collectUnknownKeys("", document, schema, &problems)
+	sort.Strings(problems)
+	return problems
+}
+
+func collectUnknownKeys(prefix string, document map[string]interface{}, schema *gonfig.Schema, problems *[]string) {
+	for key, value := range document {
+		property := lookupProperty(schema, key)
+		if property == nil {
+			*problems = append(*problems, fmt.Sprintf("unknown key %s%s", prefix, key))
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(property.Properties) > 0 {
+			collectUnknownKeys(prefix+key+".", nested, property, problems)
+		}
+	}
+}
+
+// missingFields lists the required fields of typ that no source set. A
+// default sets a field, and the fields of a nested object are only required
+// once one of them is set.
+func missingFields(typ reflect.Type, prefix string, report *gonfig.Report) []string {
+	var missing []string
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		path := prefix + p.Name
+		set := isSet(report, path)
+		if p.Tag.Get("required") == "true" && !set {
+			missing = append(missing, fmt.Sprintf("required field %s is not set", path))
+		}
+		if p.Type.Kind() == reflect.Struct && set {
+			missing = append(missing, missingFields(p.Type, path+".", report)...)
+		}
+	}
+	return missing
+}
+
+// isSet tells if a source set the field at path or one of its fields.
+func isSet(report *gonfig.Report, path string) bool {
+	for _, p := range report.Provenance {
+		if (p.Path == path || strings.HasPrefix(p.Path, path+".")) && len(p.Origin.Source) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupProperty finds the property read from key ignoring case, like
+// gonfig does, including the aliases of the properties.
+func lookupProperty(schema *gonfig.Schema, key string) *gonfig.Schema {
+	for _, property := range schema.Properties {
+		if strings.EqualFold(property.Name, key) {
+			return property.Schema
+		}
+		for _, alias := range property.Schema.Aliases {
+			if strings.EqualFold(alias, key) {
+				return property.Schema
+			}
+		}
+	}
+	return nil
+}
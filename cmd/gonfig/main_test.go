@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/B4dT0bi/gonfig"
+)
+
+type serviceConf struct {
+	Port     int    `env:"PORT" default:"8080" required:"true"`
+	Host     string `json:"host" env:"HOST" alias:"SERVER_HOST"`
+	Password string `env:"PASSWORD" secret:"true"`
+	Workers  uint16
+	Database struct {
+		Host string
+		Port int `required:"true"`
+	}
+}
+
+// writeFiles writes the schema of serviceConf and the given files to a
+// temporary directory and returns their names.
+func writeFiles(t *testing.T, files map[string]string) map[string]string {
+	dir := t.TempDir()
+	schema, err := gonfig.GenerateSchema(serviceConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(schema)
+	files["schema.json"] = string(data)
+
+	names := map[string]string{}
+	for name, content := range files {
+		names[name] = filepath.Join(dir, name)
+		if err := os.WriteFile(names[name], []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return names
+}
+
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"gonfig"}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func Test_validate_should_accept_valid_file(t *testing.T) {
+	files := writeFiles(t, map[string]string{"config.yaml": "Port: 80\nhost: example.com\nDatabase:\n  Port: 5432\n"})
+
+	code, stdout, stderr := runCommand("validate", "--schema", files["schema.json"], files["config.yaml"])
+
+	if code != 0 || !strings.HasSuffix(stdout, "config.yaml is valid\n") {
+		t.Error("the file should be valid", code, stdout, stderr)
+	}
+}
+
+func Test_validate_should_report_problems(t *testing.T) {
+	files := writeFiles(t, map[string]string{
+		"unknown.yaml":  "Port: 80\nPrts: 1\nDatabase:\n  Hots: db\n",
+		"invalid.yaml":  "Port: eighty\n",
+		"negative.yaml": "Workers: -1\n",
+		"alias.yaml":    "SERVER_HOST: example.com\n",
+		"missing.yaml":  "Database:\n  Host: db\n",
+	})
+	tests := map[string]string{
+		"unknown.yaml":  "unknown key Database.Hots\nunknown key Prts\n",
+		"invalid.yaml":  "could not read configuration file",
+		"negative.yaml": "could not read configuration file",
+		"alias.yaml":    `file key "SERVER_HOST" is deprecated`,
+		"missing.yaml":  "required field Database.Port is not set",
+	}
+	for name, message := range tests {
+		code, _, stderr := runCommand("validate", "-s", files["schema.json"], files[name])
+		if code != 1 || !strings.Contains(stderr, message) {
+			t.Errorf("%s should be invalid with %q: %d %s", name, message, code, stderr)
+		}
+	}
+}
+
+func Test_print_should_merge_env_file(t *testing.T) {
+	files := writeFiles(t, map[string]string{
+		"config.yaml": "Port: 80\nhost: example.com\n",
+		"prod.env":    "# production\nexport PORT=443\nPASSWORD=\"hunter2\"\n",
+	})
+
+	code, stdout, stderr := runCommand("print", "-s", files["schema.json"], "--env-file", files["prod.env"], files["config.yaml"])
+
+	expected := "Port: 443\nhost: \"example.com\"\nPassword: \"******\"\nWorkers: 0\nDatabase:\n  Host: \"\"\n  Port: 0\n"
+	if code != 0 || stdout != expected {
+		t.Error("unexpected configuration", code, stdout, stderr)
+	}
+
+	code, stdout, _ = runCommand("print", "-s", files["schema.json"], "--non-default", "--format", "json", files["config.yaml"])
+	if code != 0 || stdout != "{\n  \"Port\": 80,\n  \"host\": \"example.com\"\n}\n" {
+		t.Error("unexpected non-default configuration", code, stdout)
+	}
+}
+
+func Test_explain_should_show_provenance(t *testing.T) {
+	files := writeFiles(t, map[string]string{
+		"config.yaml": "Port: 80\nDatabase:\n  Host: db\n",
+		"prod.env":    "PORT=443\n",
+	})
+
+	code, stdout, stderr := runCommand("explain", "-s", files["schema.json"], "-e", files["prod.env"], files["config.yaml"])
+
+	if code != 0 || !strings.Contains(stdout, "Port = 443 from env PORT, overriding 80 from file "+files["config.yaml"]+":1, overriding 8080 from default\n") {
+		t.Error("unexpected explanation", code, stdout, stderr)
+	}
+
+	_, stdout, _ = runCommand("explain", "-s", files["schema.json"], "--field", "Database", files["config.yaml"])
+	if stdout != "Database.Host = db from file "+files["config.yaml"]+":3\nDatabase.Port = 0 (not set)\n" {
+		t.Error("unexpected explanation of Database", stdout)
+	}
+}
+
+func Test_run_should_reject_invalid_command_line(t *testing.T) {
+	if code, _, _ := runCommand(); code != 2 {
+		t.Error("a command should be required", code)
+	}
+	if code, _, stderr := runCommand("validate", "config.yaml"); code != 2 || !strings.Contains(stderr, "--schema") {
+		t.Error("the schema should be required", code, stderr)
+	}
+	if code, stdout, _ := runCommand("print", "--help"); code != 0 || !strings.Contains(stdout, "--schema") {
+		t.Error("the usage should be printed", code, stdout)
+	}
+}
+
+func Test_structOf_should_keep_large_defaults(t *testing.T) {
+	type Conf struct {
+		MaxBytes int64   `default:"10000000"`
+		Ratio    float64 `default:"0.000001"`
+		ID       uint64  `default:"18446744073709551615"`
+	}
+	schema, _ := gonfig.GenerateSchema(Conf{})
+	data, _ := json.Marshal(schema)
+	read := &gonfig.Schema{}
+	json.Unmarshal(data, read)
+
+	typ, err := structOf(read)
+	if err != nil {
+		t.Fatal("structOf unexpected error occured", err)
+	}
+	if tag := typ.Field(0).Tag.Get("default"); tag != "10000000" {
+		t.Error("unexpected default of MaxBytes", tag)
+	}
+	if tag := typ.Field(1).Tag.Get("default"); tag != "0.000001" {
+		t.Error("unexpected default of Ratio", tag)
+	}
+	if tag := typ.Field(2).Tag.Get("default"); tag != "18446744073709551615" {
+		t.Error("unexpected default of ID", tag)
+	}
+}
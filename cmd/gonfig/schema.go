@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/B4dT0bi/gonfig"
+)
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// structOf builds a struct type the object described by schema is read
+// into, with the tags gonfig needs to read it like the original struct.
+func structOf(schema *gonfig.Schema) (reflect.Type, error) {
+	var fields []reflect.StructField
+	names := map[string]bool{}
+	for _, property := range schema.Properties {
+		p := property.Schema
+		typ, err := typeOf(p)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %v", property.Name, err)
+		}
+
+		tags := []string{"json", property.Name}
+		add := func(name, value string) {
+			if len(value) > 0 {
+				tags = append(tags, name, value)
+			}
+		}
+		add("env", p.Env)
+		add("arg", p.Flag)
+		add("short", p.Short)
+		add("cmd", p.Command)
+		add("alias", strings.Join(p.Aliases, ","))
+		if p.Default != nil {
+			add("default", formatDefault(p.Default))
+		}
+		if p.Secret {
+			add("secret", "true")
+		}
+		if p.Deprecated {
+			add("deprecated", "deprecated")
+		}
+		for _, required := range schema.Required {
+			if required == property.Name {
+				add("required", "true")
+			}
+		}
+
+		var tag strings.Builder
+		for i := 0; i < len(tags); i += 2 {
+			if i > 0 {
+				tag.WriteByte(' ')
+			}
+			tag.WriteString(tags[i] + ":" + strconv.Quote(tags[i+1]))
+		}
+		fields = append(fields, reflect.StructField{Name: goName(property.Name, names), Type: typ, Tag: reflect.StructTag(tag.String())})
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// formatDefault writes a default read from JSON like a default tag. Numbers
+// are written as they were read, fmt would write a float64 as 1e+06.
+func formatDefault(value interface{}) string {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// typeOf returns the Go type values described by s are read into.
+func typeOf(s *gonfig.Schema) (reflect.Type, error) {
+	switch s.Type {
+	case "string":
+		return reflect.TypeOf(""), nil
+	case "boolean":
+		return reflect.TypeOf(false), nil
+	case "integer":
+		if s.Minimum != nil && *s.Minimum >= 0 {
+			return reflect.TypeOf(uint64(0)), nil
+		}
+		return reflect.TypeOf(int64(0)), nil
+	case "number":
+		return reflect.TypeOf(float64(0)), nil
+	case "array":
+		if s.Items == nil {
+			return reflect.TypeOf([]interface{}{}), nil
+		}
+		elem, err := typeOf(s.Items)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	case "object":
+		if len(s.Properties) > 0 {
+			typ, err := structOf(s)
+			if err != nil {
+				return nil, err
+			}
+			if len(s.Command) > 0 {
+				return reflect.PtrTo(typ), nil
+			}
+			return typ, nil
+		}
+		elem := interfaceType
+		if s.AdditionalProperties != nil {
+			var err error
+			if elem, err = typeOf(s.AdditionalProperties); err != nil {
+				return nil, err
+			}
+		}
+		return reflect.MapOf(reflect.TypeOf(""), elem), nil
+	case "":
+		return interfaceType, nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// goName returns an exported field name for the property name, unique
+// among names.
+func goName(name string, names map[string]bool) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	runes := []rune(b.String())
+	if len(runes) == 0 || !unicode.IsLetter(runes[0]) {
+		runes = append([]rune("F"), runes...)
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+
+	unique := string(runes)
+	for i := 2; names[unique]; i++ {
+		unique = string(runes) + strconv.Itoa(i)
+	}
+	names[unique] = true
+	return unique
+}
@@ -0,0 +1,103 @@
+package gonfig
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// tag name to declare a field holding the configuration of a subcommand
+const cmdTagName = "cmd"
+
+// command is the configuration of the main program or of a subcommand
+// together with its part of the command line.
+type command struct {
+	name string
+	// path is the path prefix of the fields of the configuration
+	path          string
+	configuration interface{}
+	specs         flagSpecs
+	args          *parsedArgs
+}
+
+func isCommand(p reflect.StructField) bool {
+	return len(p.Tag.Get(cmdTagName)) > 0
+}
+
+// parseArguments tokenizes the command line of the main program and of every
+// subcommand selected on it. A subcommand is selected by giving its name as
+// the first positional argument, the arguments after it belong to it.
+func (l *loader) parseArguments(configuration interface{}) error {
+	var args []string
+	if len(l.opts.Args) > 0 {
+		args = l.opts.Args[1:]
+	}
+	names := []string{getProgramNameFromArgs(l.opts.Args)}
+	path := ""
+
+	for {
+		typ := reflect.TypeOf(configuration).Elem()
+		specs, err := getFlagSpecs(typ)
+		if err != nil {
+			return err
+		}
+		parsed, err := parseArgs(args, specs)
+		if err != nil {
+			return err
+		}
+		c := &command{name: names[len(names)-1], path: path, configuration: configuration, specs: specs, args: parsed}
+		l.commands = append(l.commands, c)
+
+		if parsed.help && l.opts.AllowUnknownFlags {
+			l.report.Help = true
+		} else if parsed.help {
+			output := l.opts.HelpOutput
+			if output == nil {
+				output = os.Stdout
+			}
+			if err := printUsage(output, strings.Join(names, " "), typ, specs); err != nil {
+				return err
+			}
+			return ErrHelp
+		}
+		if len(parsed.unknown) > 0 && !l.opts.AllowUnknownFlags {
+			return newUnknownFlagError(parsed.unknown, specs)
+		}
+		if len(parsed.command) == 0 {
+			if len(names) > 1 {
+				l.report.Command = strings.Join(names[1:], " ")
+			}
+			return nil
+		}
+
+		configuration = selectCommand(reflect.ValueOf(configuration).Elem(), specs.commands[parsed.command])
+		path += typ.Field(specs.commands[parsed.command]).Name + "."
+		names = append(names, parsed.command)
+		args = parsed.rest
+	}
+}
+
+// selectCommand returns a pointer to the struct held by field i of s,
+// allocating it if the field is a nil pointer.
+func selectCommand(s reflect.Value, i int) interface{} {
+	f := s.Field(i)
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		return f.Interface()
+	}
+	return f.Addr().Interface()
+}
+
+func checkCommand(p reflect.StructField) error {
+	typ := p.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("subcommand field %s should be a struct or a pointer to a struct", p.Name)
+	}
+	return nil
+}
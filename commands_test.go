@@ -0,0 +1,133 @@
+package gonfig
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type serveConf struct {
+	Port int    `short:"p" default:"8080"`
+	Root string `env:"SERVE_ROOT"`
+}
+
+type remoteAddConf struct {
+	Name string
+}
+
+type remoteConf struct {
+	Verbose bool           `short:"v"`
+	Add     *remoteAddConf `cmd:"add"`
+}
+
+type cliConf struct {
+	Debug  bool        `short:"d"`
+	Serve  serveConf   `cmd:"serve" description:"serve the files"`
+	Remote *remoteConf `cmd:"remote" description:"manage remotes"`
+}
+
+func Test_Load_should_populate_selected_command(t *testing.T) {
+	args := []string{"app", "-d", "serve", "--Port", "80", "public"}
+	env := MapEnv(map[string]string{"SERVE_ROOT": "/srv"})
+
+	conf := cliConf{}
+	report, err := Load(&conf, Options{Args: args, LookupEnv: env})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if report.Command != "serve" {
+		t.Error("Command should be serve", report.Command)
+	}
+	if !conf.Debug {
+		t.Error("Debug should be true", conf.Debug)
+	}
+	if conf.Serve.Port != 80 {
+		t.Error("Serve.Port should be 80", conf.Serve.Port)
+	}
+	if conf.Serve.Root != "/srv" {
+		t.Error("Serve.Root should be /srv", conf.Serve.Root)
+	}
+	if conf.Remote != nil {
+		t.Error("Remote should not be allocated", conf.Remote)
+	}
+	if !reflect.DeepEqual(report.Args, []string{"public"}) {
+		t.Error("unexpected positional arguments", report.Args)
+	}
+}
+
+func Test_Load_should_populate_nested_commands(t *testing.T) {
+	args := []string{"app", "remote", "-v", "add", "--Name=origin"}
+
+	conf := cliConf{}
+	report, err := Load(&conf, Options{Args: args})
+
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+	if report.Command != "remote add" {
+		t.Error("Command should be remote add", report.Command)
+	}
+	if !conf.Remote.Verbose {
+		t.Error("Remote.Verbose should be true", conf.Remote.Verbose)
+	}
+	if conf.Remote.Add.Name != "origin" {
+		t.Error("Remote.Add.Name should be origin", conf.Remote.Add.Name)
+	}
+	if conf.Serve.Port != 0 {
+		t.Error("defaults of unselected commands should not be set", conf.Serve.Port)
+	}
+}
+
+func Test_Load_should_not_select_command_after_positional(t *testing.T) {
+	args := []string{"app", "file", "serve"}
+
+	conf := cliConf{}
+	report, _ := Load(&conf, Options{Args: args})
+
+	if report.Command != "" {
+		t.Error("no command should be selected", report.Command)
+	}
+	if !reflect.DeepEqual(report.Args, []string{"file", "serve"}) {
+		t.Error("unexpected positional arguments", report.Args)
+	}
+}
+
+func Test_Load_should_print_command_usage(t *testing.T) {
+	args := []string{"app", "serve", "--help"}
+
+	output := &bytes.Buffer{}
+	conf := cliConf{}
+	_, err := Load(&conf, Options{Args: args, HelpOutput: output})
+
+	if err != ErrHelp {
+		t.Error("Load should return ErrHelp", err)
+	}
+	if !strings.HasPrefix(output.String(), "Usage: app serve [flags]") {
+		t.Error("unexpected usage", output.String())
+	}
+	if !strings.Contains(output.String(), "--Port") {
+		t.Error("usage should list the flags of serve", output.String())
+	}
+}
+
+func Test_printUsage_should_list_commands(t *testing.T) {
+	specs, _ := getFlagSpecs(reflect.TypeOf(cliConf{}))
+	output := &bytes.Buffer{}
+	printUsage(output, "app", reflect.TypeOf(cliConf{}), specs)
+
+	usage := strings.Join(strings.Fields(output.String()), " ")
+	if !strings.Contains(usage, "Commands: serve serve the files remote manage remotes") {
+		t.Error("usage should list the commands", output.String())
+	}
+}
+
+func Test_getFlagSpecs_should_reject_non_struct_command(t *testing.T) {
+	type Conf struct {
+		Serve string `cmd:"serve"`
+	}
+	if _, err := getFlagSpecs(reflect.TypeOf(Conf{})); err == nil {
+		t.Error("getFlagSpecs should reject a command that is not a struct")
+	}
+}
@@ -0,0 +1,95 @@
+package gonfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Format identifies the syntax of configuration data.
+type Format string
+
+// The supported formats, YAML is used if none is given.
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	// FormatTOML is only written by GenerateSample, configurations can't be
+	// read from TOML.
+	FormatTOML Format = "toml"
+)
+
+// GetConfFromBytes aggregates the values of data in the given format with the
+// default, argument and environment variable values and puts them into the
+// passed interface. Unknown command-line flags are ignored.
+func GetConfFromBytes(data []byte, format Format, configuration interface{}) (err error) {
+	_, err = Load(configuration, Options{Data: data, Format: format, AllowUnknownFlags: true})
+	return
+}
+
+// GetConfFromReader is like GetConfFromBytes but reads the data from r.
+func GetConfFromReader(r io.Reader, format Format, configuration interface{}) (err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	return GetConfFromBytes(data, format, configuration)
+}
+
+// getFormat guesses the format of a file from its extension.
+func getFormat(filename string) Format {
+	if u, err := url.Parse(filename); err == nil && isURL(filename) {
+		filename = u.Path
+	}
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+func unmarshal(data []byte, format Format, v interface{}) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	switch format {
+	case FormatYAML, "":
+		return yaml.Unmarshal(data, v)
+	case FormatJSON:
+		return json.Unmarshal(data, v)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// marshalObject writes a JSON object with n members in order, field returns
+// the name and the value of member i. Maps can't keep the order of the
+// fields of a struct.
+func marshalObject(n int, field func(i int) (string, interface{})) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, value := field(i)
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(data)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
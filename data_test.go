@@ -0,0 +1,99 @@
+package gonfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_GetConfFromBytes_should_read_yaml(t *testing.T) {
+	type Conf struct {
+		Port int
+		Host string `default:"fromDefault"`
+	}
+	conf := Conf{}
+	err := GetConfFromBytes([]byte("Port: 8080"), FormatYAML, &conf)
+
+	if err != nil {
+		t.Error("GetConfFromBytes unexpected error occured", err)
+	}
+	if conf.Port != 8080 {
+		t.Error("Port should be 8080", conf.Port)
+	}
+	if conf.Host != "fromDefault" {
+		t.Error("Host should be fromDefault", conf.Host)
+	}
+}
+
+func Test_GetConfFromReader_should_read_json(t *testing.T) {
+	type Conf struct {
+		Port int
+		Host string
+	}
+	conf := Conf{}
+	err := GetConfFromReader(strings.NewReader(`{"Port": 8080, "Host": "hallo"}`), FormatJSON, &conf)
+
+	if err != nil {
+		t.Error("GetConfFromReader unexpected error occured", err)
+	}
+	if conf.Port != 8080 {
+		t.Error("Port should be 8080", conf.Port)
+	}
+	if conf.Host != "hallo" {
+		t.Error("Host should be hallo", conf.Host)
+	}
+}
+
+func Test_GetConfFromBytes_should_fail_on_invalid_data(t *testing.T) {
+	type Conf struct {
+		Port int
+	}
+	conf := Conf{}
+
+	if err := GetConfFromBytes([]byte("Port: abc"), FormatYAML, &conf); err == nil {
+		t.Error("GetConfFromBytes should fail on invalid data")
+	}
+	if err := GetConfFromBytes([]byte("Port: 1"), Format("toml"), &conf); err == nil {
+		t.Error("GetConfFromBytes should fail on unknown formats")
+	}
+}
+
+func Test_Load_should_read_file_over_data(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent(`{"TestString": "fromFile"}`, t)
+	os.Rename(filename, filename+".json")
+	filename += ".json"
+	defer os.Remove(filename)
+
+	type Conf struct {
+		ID         int
+		TestString string
+	}
+	conf := Conf{}
+	_, err := Load(&conf, Options{
+		Data:      []byte("ID: 1\nTestString: fromData"),
+		Filename:  filename,
+		Args:      []string{"cmd"},
+		LookupEnv: MapEnv(nil),
+	})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.ID != 1 {
+		t.Error("ID should be 1", conf.ID)
+	}
+	if conf.TestString != "fromFile" {
+		t.Error("TestString should be fromFile", conf.TestString)
+	}
+}
+
+func Test_getFormat_should_detect_json(t *testing.T) {
+	if getFormat("config.JSON") != FormatJSON {
+		t.Error("config.JSON should be read as JSON")
+	}
+	if getFormat("config.yml") != FormatYAML {
+		t.Error("config.yml should be read as YAML")
+	}
+}
@@ -0,0 +1,60 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Change describes a field whose value differs between two configurations.
+type Change struct {
+	// Path is the name of the field, prefixed with the names of the structs
+	// holding it, like "Database.Host".
+	Path string
+	// Old and New are the values of the field, secrets are replaced by a mask.
+	Old interface{}
+	New interface{}
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// Diff returns the fields whose values differ between old and new, walking
+// into nested structs. Both must be structs or pointers to structs of the
+// same type, Diff panics otherwise.
+func Diff(old, new interface{}) []Change {
+	oldValue := reflect.Indirect(reflect.ValueOf(old))
+	newValue := reflect.Indirect(reflect.ValueOf(new))
+	if oldValue.Kind() != reflect.Struct || oldValue.Type() != newValue.Type() {
+		panic(fmt.Sprintf("gonfig: Diff of %T and %T, they should be structs of the same type", old, new))
+	}
+	return diffStruct("", oldValue, newValue, nil)
+}
+
+func diffStruct(prefix string, old, new reflect.Value, changes []Change) []Change {
+	typ := old.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		if len(p.PkgPath) > 0 {
+			continue
+		}
+		path := prefix + p.Name
+		oldField, newField := old.Field(i), new.Field(i)
+
+		if elem, ok := structType(p.Type); ok && !isSecret(p) {
+			changes = diffStruct(path+".", structValue(oldField, elem), structValue(newField, elem), changes)
+			continue
+		}
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+
+		// the secrets held by slices and maps of structs are masked too
+		change := Change{Path: path, Old: masked(oldField), New: masked(newField)}
+		if isSecret(p) {
+			change.Old, change.New = redacted, redacted
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,96 @@
+package gonfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type diffDatabase struct {
+	Host     string
+	Password string `secret:"true"`
+}
+
+type diffConf struct {
+	Port     int
+	Tags     []string
+	Database diffDatabase
+	Serve    *serveConf
+	Started  time.Time
+	internal int
+}
+
+func Test_Diff_should_report_changed_fields(t *testing.T) {
+	old := &diffConf{Port: 80, Tags: []string{"a"}, Database: diffDatabase{Host: "db1", Password: "old"}, internal: 1}
+	new := &diffConf{Port: 81, Tags: []string{"a"}, Database: diffDatabase{Host: "db2", Password: "new"}, internal: 2}
+
+	changes := Diff(old, new)
+
+	expected := []Change{
+		{Path: "Port", Old: 80, New: 81},
+		{Path: "Database.Host", Old: "db1", New: "db2"},
+		{Path: "Database.Password", Old: redacted, New: redacted},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Error("unexpected changes", changes)
+	}
+}
+
+func Test_Diff_should_mask_secrets_in_containers(t *testing.T) {
+	type Conf struct {
+		DBs []diffDatabase
+	}
+	old := Conf{DBs: []diffDatabase{{Host: "db", Password: "hunter2"}}}
+	new := Conf{DBs: []diffDatabase{{Host: "db", Password: "s3cret"}}}
+
+	changes := Diff(old, new)
+
+	if len(changes) != 1 || changes[0].Path != "DBs" {
+		t.Fatal("unexpected changes", changes)
+	}
+	if s := changes[0].String(); strings.Contains(s, "hunter2") || strings.Contains(s, "s3cret") || !strings.Contains(s, "db") {
+		t.Error("the secrets should be masked", s)
+	}
+}
+
+func Test_Diff_should_report_nothing_for_equal_configurations(t *testing.T) {
+	old := diffConf{Port: 80, Tags: []string{"a", "b"}}
+	new := diffConf{Port: 80, Tags: []string{"a", "b"}}
+
+	if changes := Diff(old, &new); len(changes) != 0 {
+		t.Error("there should be no changes", changes)
+	}
+}
+
+func Test_Diff_should_walk_into_nil_pointers(t *testing.T) {
+	old := &diffConf{}
+	new := &diffConf{Serve: &serveConf{Port: 8080}}
+
+	changes := Diff(old, new)
+
+	expected := []Change{{Path: "Serve.Port", Old: 0, New: 8080}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Error("unexpected changes", changes)
+	}
+}
+
+func Test_Diff_should_panic_on_different_types(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Diff should panic on different types")
+		}
+	}()
+	Diff(&diffConf{}, &serveConf{})
+}
+
+func Test_Diff_should_compare_structs_without_exported_fields(t *testing.T) {
+	old := &diffConf{Started: time.Unix(0, 0)}
+	new := &diffConf{Started: time.Unix(1, 0)}
+
+	changes := Diff(old, new)
+
+	if len(changes) != 1 || changes[0].Path != "Started" {
+		t.Error("unexpected changes", changes)
+	}
+}
@@ -0,0 +1,231 @@
+package gonfig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Decrypter decrypts the values of configuration files written as
+// ENC[algorithm,parameters...], so files holding credentials can be
+// committed. Implementations can call a key management service.
+type Decrypter interface {
+	// Decrypt returns the plaintext of value, the whole ENC[...] text.
+	Decrypt(value string) (string, error)
+}
+
+const encryptedPrefix = "ENC["
+const encryptedSuffix = "]"
+
+// algorithm of the values KeyDecrypter reads and writes
+const aes256GCM = "AES256_GCM"
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) && strings.HasSuffix(value, encryptedSuffix)
+}
+
+// hasEncrypted tells if value, a document or a part of it, holds an
+// encrypted string.
+func hasEncrypted(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return isEncrypted(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			if hasEncrypted(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if hasEncrypted(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decryptError is returned for an encrypted value that can't be decrypted.
+// Unlike the other errors reading a file, it makes Load fail even if the
+// file isn't required, as the file is there but can't be used.
+type decryptError struct {
+	path string
+	err  error
+}
+
+func (e *decryptError) Error() string {
+	return fmt.Sprintf("could not decrypt %s: %v", e.path, e.err)
+}
+
+func isDecryptError(err error) bool {
+	_, ok := err.(*decryptError)
+	return ok
+}
+
+// decrypt returns a copy of value, a document or a part of it read into typ,
+// with its encrypted strings decrypted. A decrypted value read into a field
+// that isn't a string is parsed like a YAML scalar, so numbers and booleans
+// can be encrypted too. typ is nil for values not read into a field.
+func (l *loader) decrypt(path string, typ reflect.Type, value interface{}) (interface{}, error) {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch v := value.(type) {
+	case string:
+		if !isEncrypted(v) {
+			return v, nil
+		}
+		if l.opts.Decrypter == nil {
+			return nil, &decryptError{path: path, err: errors.New("no decrypter is set")}
+		}
+		plaintext, err := l.opts.Decrypter.Decrypt(v)
+		if err != nil {
+			return nil, &decryptError{path: path, err: err}
+		}
+		if typ != nil && typ.Kind() != reflect.String && typ.Kind() != reflect.Interface {
+			var scalar interface{}
+			if yaml.Unmarshal([]byte(plaintext), &scalar) == nil {
+				return scalar, nil
+			}
+		}
+		return plaintext, nil
+
+	case map[string]interface{}:
+		decrypted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			var err error
+			if decrypted[key], err = l.decrypt(joinPath(path, key), fieldType(typ, key), item); err != nil {
+				return nil, err
+			}
+		}
+		return decrypted, nil
+
+	case []interface{}:
+		var elem reflect.Type
+		if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			elem = typ.Elem()
+		}
+		decrypted := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if decrypted[i], err = l.decrypt(fmt.Sprintf("%s[%d]", path, i), elem, item); err != nil {
+				return nil, err
+			}
+		}
+		return decrypted, nil
+	}
+	return value, nil
+}
+
+// fieldType returns the type of the value read from key into typ, or nil if
+// key isn't read into typ.
+func fieldType(typ reflect.Type, key string) reflect.Type {
+	if typ == nil {
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Map:
+		return typ.Elem()
+	case reflect.Struct:
+		if p, _, ok := lookupField(typ, key); ok {
+			return p.Type
+		}
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+	return path + "." + key
+}
+
+// KeyDecrypter encrypts and decrypts values with AES-256 in GCM mode, written
+// as ENC[AES256_GCM,data:...,iv:...,tag:...] with base64 encoded parts.
+type KeyDecrypter struct {
+	aead cipher.AEAD
+}
+
+// NewKeyDecrypter returns a KeyDecrypter using key, which must be 32 bytes.
+func NewKeyDecrypter(key []byte) (*KeyDecrypter, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key should be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyDecrypter{aead: aead}, nil
+}
+
+// NewKeyFileDecrypter returns a KeyDecrypter using the base64 encoded key
+// held by the file filename, as written by "openssl rand -base64 32".
+func NewKeyFileDecrypter(filename string) (*KeyDecrypter, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode key file %s: %v", filename, err)
+	}
+	return NewKeyDecrypter(key)
+}
+
+// Encrypt returns plaintext encrypted as an ENC[...] value.
+func (d *KeyDecrypter) Encrypt(plaintext string) (string, error) {
+	iv := make([]byte, d.aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := d.aead.Seal(nil, iv, []byte(plaintext), nil)
+	data, tag := sealed[:len(plaintext)], sealed[len(plaintext):]
+
+	encode := base64.StdEncoding.EncodeToString
+	return encryptedPrefix + aes256GCM + ",data:" + encode(data) + ",iv:" + encode(iv) + ",tag:" + encode(tag) + encryptedSuffix, nil
+}
+
+// Decrypt returns the plaintext of an ENC[AES256_GCM,...] value.
+func (d *KeyDecrypter) Decrypt(value string) (string, error) {
+	if !isEncrypted(value) {
+		return "", errors.New("value is not encrypted")
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, encryptedPrefix), encryptedSuffix), ",")
+	if parts[0] != aes256GCM {
+		return "", fmt.Errorf("unsupported algorithm %q", parts[0])
+	}
+
+	fields := map[string][]byte{}
+	for _, part := range parts[1:] {
+		key, encoded, _ := cut(part, ":")
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %v", key, err)
+		}
+		fields[key] = decoded
+	}
+	if len(fields["iv"]) != d.aead.NonceSize() || len(fields["tag"]) != d.aead.Overhead() {
+		return "", errors.New("invalid iv or tag")
+	}
+
+	plaintext, err := d.aead.Open(nil, fields["iv"], append(fields["data"], fields["tag"]...), nil)
+	if err != nil {
+		return "", errors.New("message authentication failed")
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,153 @@
+package gonfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestDecrypter(t *testing.T) *KeyDecrypter {
+	d, err := NewKeyDecrypter(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal("NewKeyDecrypter unexpected error occured", err)
+	}
+	return d
+}
+
+func encryptValue(t *testing.T, d *KeyDecrypter, plaintext string) string {
+	value, err := d.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal("Encrypt unexpected error occured", err)
+	}
+	return value
+}
+
+func Test_KeyDecrypter_should_decrypt_encrypted_values(t *testing.T) {
+	d := newTestDecrypter(t)
+	value := encryptValue(t, d, "hunter2")
+
+	if !strings.HasPrefix(value, "ENC[AES256_GCM,data:") || strings.Contains(value, "hunter2") {
+		t.Error("unexpected encrypted value", value)
+	}
+	if plaintext, err := d.Decrypt(value); err != nil || plaintext != "hunter2" {
+		t.Error("Decrypt should return the plaintext", plaintext, err)
+	}
+
+	other, _ := NewKeyDecrypter(bytes.Repeat([]byte{8}, 32))
+	if _, err := other.Decrypt(value); err == nil {
+		t.Error("Decrypt should fail with another key")
+	}
+	if _, err := d.Decrypt(strings.Replace(value, "AES256_GCM", "PGP", 1)); err == nil {
+		t.Error("Decrypt should fail on other algorithms")
+	}
+}
+
+func Test_Load_should_decrypt_file_values(t *testing.T) {
+	type Conf struct {
+		Password string
+		Pin      string
+		Port     int
+		Database struct {
+			Users []string
+		}
+	}
+	d := newTestDecrypter(t)
+	data := "Password: " + encryptValue(t, d, "hunter2") +
+		"\nPin: " + encryptValue(t, d, "0123") +
+		"\nPort: " + encryptValue(t, d, "8080") +
+		"\nDatabase:\n  Users:\n    - admin\n    - " + encryptValue(t, d, "root") + "\n"
+	fsys := fstest.MapFS{"config.yaml": &fstest.MapFile{Data: []byte(data)}}
+
+	conf := Conf{}
+	report, err := Load(&conf, Options{Filename: "config.yaml", FS: fsys, RequireFile: true, Decrypter: d, Args: []string{"app"}})
+
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+	if conf.Password != "hunter2" || conf.Pin != "0123" || conf.Port != 8080 {
+		t.Error("the values should be decrypted", conf)
+	}
+	if len(conf.Database.Users) != 2 || conf.Database.Users[1] != "root" {
+		t.Error("the values of lists should be decrypted", conf.Database.Users)
+	}
+	if p, _ := report.Lookup("Password"); !isEncrypted(p.Origin.Value) || p.Origin.Line != 1 {
+		t.Error("the report should show the encrypted value", p.Origin)
+	}
+}
+
+func Test_Load_should_not_interpolate_decrypted_values(t *testing.T) {
+	type Conf struct {
+		Password string
+		Users    []string
+		Host     string
+	}
+	d := newTestDecrypter(t)
+	data := []byte("Password: " + encryptValue(t, d, "p@${ss}word") +
+		"\nUsers:\n  - " + encryptValue(t, d, "${.Host}") + "\nHost: ${HOST}\n")
+
+	conf := Conf{}
+	_, err := Load(&conf, Options{Data: data, Decrypter: d, Args: []string{"app"}, LookupEnv: MapEnv(map[string]string{"HOST": "db"})})
+
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+	if conf.Password != "p@${ss}word" || len(conf.Users) != 1 || conf.Users[0] != "${.Host}" {
+		t.Error("decrypted values should be taken literally", conf.Password, conf.Users)
+	}
+	if conf.Host != "db" {
+		t.Error("the other values should still be expanded", conf.Host)
+	}
+}
+
+func Test_Load_should_fail_on_encrypted_values_without_decrypter(t *testing.T) {
+	type Conf struct {
+		Password string
+	}
+	data := []byte("Password: " + encryptValue(t, newTestDecrypter(t), "hunter2"))
+
+	conf := Conf{}
+	_, err := Load(&conf, Options{Data: data, Args: []string{"app"}})
+
+	if err == nil || !strings.Contains(err.Error(), "Password") {
+		t.Error("Load should fail without a decrypter", err)
+	}
+}
+
+func Test_Load_should_fail_on_undecryptable_file_values(t *testing.T) {
+	type Conf struct {
+		Password string
+	}
+	data := []byte("Password: " + encryptValue(t, newTestDecrypter(t), "hunter2"))
+	fsys := fstest.MapFS{"config.yaml": &fstest.MapFile{Data: data}}
+	other, _ := NewKeyDecrypter(bytes.Repeat([]byte{8}, 32))
+
+	for _, decrypter := range []Decrypter{nil, other} {
+		conf := Conf{}
+		_, err := Load(&conf, Options{Filename: "config.yaml", FS: fsys, Decrypter: decrypter, Args: []string{"app"}, Logger: &recordingLogger{}})
+
+		if err == nil || !strings.Contains(err.Error(), "could not decrypt Password") {
+			t.Error("Load should fail on values it can't decrypt even if the file isn't required", err)
+		}
+	}
+}
+
+func Test_NewKeyFileDecrypter_should_read_base64_key(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "key")
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	if err := os.WriteFile(filename, []byte(key+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewKeyFileDecrypter(filename)
+	if err != nil {
+		t.Fatal("NewKeyFileDecrypter unexpected error occured", err)
+	}
+	value := encryptValue(t, newTestDecrypter(t), "hunter2")
+	if plaintext, _ := d.Decrypt(value); plaintext != "hunter2" {
+		t.Error("the key file should hold the same key", plaintext)
+	}
+}
@@ -0,0 +1,152 @@
+package gonfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// EnvVar describes an environment variable read into a configuration.
+type EnvVar struct {
+	Name string
+	// Field is the path of the keys of the field in a file, like
+	// "Serve.Port" for the field Port of the subcommand held by Serve.
+	Field string
+	// Command is the subcommand that reads the variable, it is empty for the
+	// main configuration.
+	Command     string
+	Type        string
+	Default     string
+	Description string
+	Required    bool
+	Secret      bool
+	// Deprecated is set for the variables of deprecated fields and for
+	// aliases, which name the variable to use instead in Description.
+	Deprecated bool
+}
+
+// EnvVars lists the environment variables read into the object described by
+// s and into its subcommands, in the order of the fields. The aliases of a
+// variable follow it. Each variable can also be read from the file named by
+// the same variable with a _FILE suffix.
+func (s *Schema) EnvVars() []EnvVar {
+	return s.envVars("", "")
+}
+
+func (s *Schema) envVars(prefix string, command string) []EnvVar {
+	var vars []EnvVar
+	for _, property := range s.Properties {
+		p := property.Schema
+		if len(p.Command) > 0 {
+			vars = append(vars, p.envVars(prefix+property.Name+".", strings.TrimSpace(command+" "+p.Command))...)
+			continue
+		}
+		if len(p.Env) == 0 {
+			continue
+		}
+
+		v := EnvVar{
+			Name:        p.Env,
+			Field:       prefix + property.Name,
+			Command:     command,
+			Type:        describeType(p),
+			Description: p.Description,
+			Required:    isRequiredProperty(s, property.Name),
+			Secret:      p.Secret,
+			Deprecated:  p.Deprecated,
+		}
+		if p.Default != nil {
+			v.Default = fmt.Sprint(p.Default)
+		}
+		vars = append(vars, v)
+
+		for _, alias := range p.Aliases {
+			deprecated := v
+			deprecated.Name = alias
+			deprecated.Required = false
+			deprecated.Deprecated = true
+			deprecated.Description = "deprecated, use " + v.Name + " instead"
+			vars = append(vars, deprecated)
+		}
+	}
+	return vars
+}
+
+// WriteEnvMarkdown writes vars as a Markdown table.
+func WriteEnvMarkdown(w io.Writer, vars []EnvVar) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "| Variable | Field | Type | Default | Description |")
+	fmt.Fprintln(b, "| --- | --- | --- | --- | --- |")
+	for _, v := range vars {
+		var notes []string
+		if len(v.Command) > 0 {
+			notes = append(notes, "Only read by "+v.Command+".")
+		}
+		if v.Required {
+			notes = append(notes, "Required.")
+		}
+		if v.Secret {
+			notes = append(notes, "Secret.")
+		}
+		if v.Deprecated && !strings.HasPrefix(v.Description, "deprecated") {
+			notes = append(notes, "Deprecated.")
+		}
+		description := strings.TrimSpace(v.Description + " " + strings.Join(notes, " "))
+
+		def := ""
+		if len(v.Default) > 0 {
+			def = "`" + v.Default + "`"
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s |\n", v.Name, escapeMarkdown(v.Field), v.Type, escapeMarkdown(def), escapeMarkdown(description))
+	}
+	return b.Flush()
+}
+
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// WriteEnvExample writes vars as a .env file setting every variable to its
+// default, preceded by comments holding its description and type. Deprecated
+// variables are left out.
+func WriteEnvExample(w io.Writer, vars []EnvVar) error {
+	b := bufio.NewWriter(w)
+	written := 0
+	for _, v := range vars {
+		if v.Deprecated {
+			continue
+		}
+		if written > 0 {
+			b.WriteByte('\n')
+		}
+		written++
+
+		for _, line := range strings.Split(v.Description, "\n") {
+			if len(line) > 0 {
+				fmt.Fprintf(b, "# %s\n", line)
+			}
+		}
+		details := []string{v.Type}
+		if len(v.Command) > 0 {
+			details = append(details, "only read by "+v.Command)
+		}
+		if v.Required {
+			details = append(details, "required")
+		}
+		if v.Secret {
+			details = append(details, "secret")
+		}
+		fmt.Fprintf(b, "# %s\n%s=%s\n", strings.Join(details, ", "), v.Name, formatEnvValue(v.Default))
+	}
+	return b.Flush()
+}
+
+// formatEnvValue quotes values a .env parser would otherwise cut or change.
+func formatEnvValue(value string) string {
+	if strings.ContainsAny(value, " \t\n#\"'$\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
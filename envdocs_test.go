@@ -0,0 +1,65 @@
+package gonfig
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type envDocsConf struct {
+	Port     int    `env:"PORT" default:"8080" description:"port to listen on" required:"true"`
+	Host     string `env:"HOST" alias:"SERVER_HOST" default:"my host"`
+	Password string `env:"PASSWORD" secret:"true" default:"dev"`
+	Tags     []string
+	Serve    serveConf `cmd:"serve"`
+}
+
+func Test_Schema_EnvVars_should_list_variables(t *testing.T) {
+	schema, _ := GenerateSchema(envDocsConf{})
+
+	expected := []EnvVar{
+		{Name: "PORT", Field: "Port", Type: "integer", Default: "8080", Description: "port to listen on", Required: true},
+		{Name: "HOST", Field: "Host", Type: "string", Default: "my host"},
+		{Name: "SERVER_HOST", Field: "Host", Type: "string", Default: "my host", Description: "deprecated, use HOST instead", Deprecated: true},
+		{Name: "PASSWORD", Field: "Password", Type: "string", Secret: true},
+		{Name: "Port", Field: "Serve.Port", Command: "serve", Type: "integer", Default: "8080"},
+		{Name: "SERVE_ROOT", Field: "Serve.Root", Command: "serve", Type: "string"},
+	}
+	if vars := schema.EnvVars(); !reflect.DeepEqual(vars, expected) {
+		t.Errorf("unexpected variables %+v", vars)
+	}
+}
+
+func Test_WriteEnvMarkdown_should_write_table(t *testing.T) {
+	schema, _ := GenerateSchema(envDocsConf{})
+	var b bytes.Buffer
+	if err := WriteEnvMarkdown(&b, schema.EnvVars()[:3]); err != nil {
+		t.Fatal("WriteEnvMarkdown unexpected error occured", err)
+	}
+
+	expected := "| Variable | Field | Type | Default | Description |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| `PORT` | Port | integer | `8080` | port to listen on Required. |\n" +
+		"| `HOST` | Host | string | `my host` |  |\n" +
+		"| `SERVER_HOST` | Host | string | `my host` | deprecated, use HOST instead |\n"
+	if b.String() != expected {
+		t.Error("unexpected table", b.String())
+	}
+}
+
+func Test_WriteEnvExample_should_write_defaults(t *testing.T) {
+	schema, _ := GenerateSchema(envDocsConf{})
+	var b bytes.Buffer
+	if err := WriteEnvExample(&b, schema.EnvVars()); err != nil {
+		t.Fatal("WriteEnvExample unexpected error occured", err)
+	}
+
+	expected := "# port to listen on\n# integer, required\nPORT=8080\n\n" +
+		"# string\nHOST=\"my host\"\n\n" +
+		"# string, secret\nPASSWORD=\n\n" +
+		"# integer, only read by serve\nPort=8080\n\n" +
+		"# string, only read by serve\nSERVE_ROOT=\n"
+	if b.String() != expected {
+		t.Error("unexpected .env", b.String())
+	}
+}
@@ -0,0 +1,126 @@
+package gonfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tag name of the human readable description of a field
+const descriptionTagName = "description"
+
+// fieldInfo gathers what the sources know about a configuration field.
+type fieldInfo struct {
+	field       reflect.StructField
+	flag        string
+	short       string
+	env         string
+	def         string
+	description string
+	// position is the pos tag of a field bound to a positional argument.
+	position string
+}
+
+// getFieldInfos describes the fields of typ the sources can set.
+func getFieldInfos(typ reflect.Type) []fieldInfo {
+	var infos []fieldInfo
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		if p.Anonymous || len(p.PkgPath) > 0 || isCommand(p) {
+			continue
+		}
+		infos = append(infos, fieldInfo{
+			field:       p,
+			flag:        getKey(p, argTagName),
+			short:       p.Tag.Get(shortTagName),
+			env:         getKey(p, envTagName),
+			def:         mask(p, p.Tag.Get(defaultTagName)),
+			description: p.Tag.Get(descriptionTagName),
+			position:    p.Tag.Get(posTagName),
+		})
+	}
+	return infos
+}
+
+// typeName is the name of the type of a field as shown to users.
+func (f fieldInfo) typeName() string {
+	return f.field.Type.Kind().String()
+}
+
+// structType returns the struct type held by a field of type typ, directly
+// or through a pointer. Structs without exported fields, like time.Time,
+// are values of their own.
+func structType(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return typ, false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if len(typ.Field(i).PkgPath) == 0 {
+			return typ, true
+		}
+	}
+	return typ, false
+}
+
+// structValue dereferences v, a nil pointer is a zero struct of type typ.
+func structValue(v reflect.Value, typ reflect.Type) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(typ)
+		}
+		return v.Elem()
+	}
+	return v
+}
+
+// walkLeaves calls fn for every exported field of the struct v that doesn't
+// hold a struct itself, walking into nested structs and non-nil pointers to
+// them. Secret structs are leaves, their fields are not shown on their own.
+func walkLeaves(prefix string, v reflect.Value, fn func(path string, p reflect.StructField, v reflect.Value)) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		if len(p.PkgPath) > 0 {
+			continue
+		}
+		f := v.Field(i)
+		if _, ok := structType(p.Type); ok && !isSecret(p) {
+			if f.Kind() == reflect.Ptr {
+				if f.IsNil() {
+					continue
+				}
+				f = f.Elem()
+			}
+			walkLeaves(prefix+p.Name+".", f, fn)
+			continue
+		}
+		fn(prefix+p.Name, p, f)
+	}
+}
+
+// lookupField returns the exported field of the struct typ a document key is
+// read into, matching its file key or one of its aliases case-insensitively,
+// and its path from typ. Fields of embedded structs are prefixed by the
+// name of the struct, like the paths of the report.
+func lookupField(typ reflect.Type, key string) (reflect.StructField, string, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		if len(p.PkgPath) > 0 {
+			continue
+		}
+		if elem, ok := structType(p.Type); ok && p.Anonymous {
+			if found, path, ok := lookupField(elem, key); ok {
+				return found, p.Name + "." + path, true
+			}
+			continue
+		}
+		for _, name := range append([]string{getFileKey(p)}, getAliases(p)...) {
+			if strings.EqualFold(name, key) {
+				return p, p.Name, true
+			}
+		}
+	}
+	return reflect.StructField{}, "", false
+}
@@ -0,0 +1,21 @@
+package gonfig
+
+import (
+	"io/fs"
+	"os"
+)
+
+// GetConfFromFS is like GetConfByFilename but reads the file from fsys,
+// which can be an embed.FS or any other file system.
+func GetConfFromFS(fsys fs.FS, filename string, configuration interface{}) (err error) {
+	_, err = Load(configuration, Options{Filename: filename, FS: fsys, AllowUnknownFlags: true})
+	return
+}
+
+// osFS opens files of the operating system. Unlike os.DirFS it isn't rooted,
+// so names can be absolute or relative to the working directory.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
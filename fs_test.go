@@ -0,0 +1,79 @@
+package gonfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func Test_Load_should_read_file_from_fs(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"config/app.yaml": &fstest.MapFile{Data: []byte("ID: 123\nTestString: hallo")},
+	}
+	type Conf struct {
+		ID         int
+		TestString string
+	}
+	conf := Conf{}
+	_, err := Load(&conf, Options{Filename: "config/app.yaml", FS: fsys, Args: []string{"cmd"}, LookupEnv: MapEnv(nil)})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.ID != 123 {
+		t.Error("ID should be 123", conf.ID)
+	}
+	if conf.TestString != "hallo" {
+		t.Error("TestString should be hallo", conf.TestString)
+	}
+}
+
+func Test_Load_should_layer_fs_file_over_data(t *testing.T) {
+	t.Parallel()
+
+	embedded := fstest.MapFS{
+		"defaults.yaml": &fstest.MapFile{Data: []byte("ID: 1\nTestString: fromDefaults")},
+	}
+	disk := fstest.MapFS{
+		"app.yaml": &fstest.MapFile{Data: []byte("TestString: fromDisk")},
+	}
+	defaults, err := embedded.ReadFile("defaults.yaml")
+	if err != nil {
+		t.Fatal("Error reading embedded defaults", err)
+	}
+
+	type Conf struct {
+		ID         int
+		TestString string
+	}
+	conf := Conf{}
+	_, err = Load(&conf, Options{Data: defaults, Filename: "app.yaml", FS: disk, Args: []string{"cmd"}, LookupEnv: MapEnv(nil)})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.ID != 1 {
+		t.Error("ID should be 1", conf.ID)
+	}
+	if conf.TestString != "fromDisk" {
+		t.Error("TestString should be fromDisk", conf.TestString)
+	}
+}
+
+func Test_Load_should_skip_missing_fs_file(t *testing.T) {
+	t.Parallel()
+
+	type Conf struct {
+		ID int `default:"1"`
+	}
+	conf := Conf{}
+	_, err := Load(&conf, Options{Filename: "missing.yaml", FS: fstest.MapFS{}, Args: []string{"cmd"}, LookupEnv: MapEnv(nil), Logger: &recordingLogger{}})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.ID != 1 {
+		t.Error("ID should be 1", conf.ID)
+	}
+}
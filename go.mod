@@ -0,0 +1,7 @@
+module github.com/B4dT0bi/gonfig
+
+go 1.19
+
+require github.com/ghodss/yaml v1.0.0
+
+require gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -3,13 +3,17 @@
 package gonfig
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
+	"log"
+	"net/http"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
-	"log"
 
 	"github.com/ghodss/yaml"
 )
@@ -19,110 +23,511 @@ const envTagName = "env"
 const argTagName = "arg"
 const defaultTagName = "default"
 
+// tag names to keep old keys working while a configuration is migrated
+const aliasTagName = "alias"
+const deprecatedTagName = "deprecated"
+
+// Source identifies where a configuration value came from.
+type Source string
+
+// The sources a value can be read from, in the order they are applied.
+const (
+	SourceDefault Source = "default"
+	SourceData    Source = "data"
+	SourceFile    Source = "file"
+	SourceKV      Source = "kv"
+	SourceArg     Source = "arg"
+	SourceEnv     Source = "env"
+)
+
+// Logger receives the warnings emitted while loading a configuration.
+// A *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type stdLogger struct{}
+
+func (stdLogger) Printf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
+
+// Options controls how Load reads a configuration.
+type Options struct {
+	// Filename is the YAML file to read, nothing is read if it is empty.
+	// Files ending in .json are read as JSON.
+	Filename string
+	// FS is the file system Filename is read from, the file system of the
+	// operating system is used if it is nil.
+	FS fs.FS
+	// RequireFile makes Load fail if Filename can't be read instead of
+	// logging it and going on without it.
+	RequireFile bool
+	// Data is read in the given Format before Filename, so the file only
+	// needs to hold the values overriding it.
+	Data   []byte
+	Format Format
+	// Args is the command line starting with the program name, os.Args is
+	// used if it is nil.
+	Args []string
+	// LookupEnv looks up environment variables, os.LookupEnv is used if it
+	// is nil.
+	LookupEnv func(key string) (string, bool)
+	// ValueFS is the file system the files named by _FILE environment
+	// variables and by fields tagged file:"true" are read from, the file
+	// system of the operating system is used if it is nil.
+	ValueFS fs.FS
+	// Decrypter decrypts the values written as ENC[...] in Filename and
+	// Data. Reading encrypted values fails if it is nil.
+	Decrypter Decrypter
+	// KV is a key/value store read after Filename, the keys under KVPrefix
+	// are mapped onto the configuration like app/database/host onto
+	// Database.Host for the prefix app/. Load fails if it can't be read.
+	KV       KV
+	KVPrefix string
+	// HTTPClient fetches Filename if it is an http:// or https:// URL, a
+	// client with a 10 second timeout is used if it is nil.
+	HTTPClient *http.Client
+	// CacheFile keeps the last configuration fetched from a URL. Its ETag
+	// is sent to only download a changed configuration, and the cached copy
+	// is read if the URL can't be fetched.
+	CacheFile string
+	// Checksum is the hex encoded SHA-256 checksum Filename must match.
+	Checksum string
+	// Logger receives warnings, the standard logger is used if it is nil.
+	Logger Logger
+	// AllowUnknownFlags ignores command-line flags the configuration does not
+	// declare instead of failing, for programs sharing them with other parsers.
+	AllowUnknownFlags bool
+	// HelpOutput receives the usage printed for --help, os.Stdout if it is nil.
+	HelpOutput io.Writer
+}
+
+// Report describes what happened while loading a configuration.
+type Report struct {
+	// Deprecations lists every deprecated key that provided a value.
+	Deprecations []Deprecation
+	// Args holds the positional command-line arguments left after the flags.
+	Args []string
+	// Command is the selected subcommand, the names of nested subcommands
+	// are separated by spaces. It is empty if no subcommand was selected.
+	Command string
+	// Help tells if the command line asked for help with --help or -h while
+	// AllowUnknownFlags is set, so the caller can print the usage with
+	// WriteUsage. Load returns ErrHelp instead otherwise.
+	Help bool
+	// Provenance lists every field with its final value and the sources
+	// that set it.
+	Provenance []Provenance
+}
+
+// Deprecation records a value that was read from a deprecated key.
+type Deprecation struct {
+	// Field is the name of the struct field the value was set on.
+	Field string
+	// Source is where the deprecated key was found.
+	Source Source
+	// Key is the deprecated key as it was found in the source.
+	Key string
+	// Message tells how to migrate away from the key.
+	Message string
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("%s key %q is deprecated: %s", d.Source, d.Key, d.Message)
+}
+
 // GetConf aggregates all the YAML and environment variable values
 // and puts them into the passed interface.
 func GetConf(configuration interface{}) (err error) {
-	GetConfByFilename(getProgramName()+".yaml", configuration)
-	return
+	return GetConfByFilename(getProgramName()+".yaml", configuration)
 }
 
 // GetConfByFilename aggregates all the YAML and environment variable values
-// and puts them into the passed interface.
+// and puts them into the passed interface. Unknown command-line flags are
+// ignored, use Load to have them reported.
 func GetConfByFilename(filename string, configuration interface{}) (err error) {
+	_, err = Load(configuration, Options{Filename: filename, AllowUnknownFlags: true})
+	return
+}
+
+// Load aggregates the default, YAML, argument and environment variable values
+// as configured by opts, puts them into the passed interface and reports
+// what happened along the way.
+func Load(configuration interface{}, opts Options) (*Report, error) {
 
 	configValue := reflect.ValueOf(configuration)
 	if typ := configValue.Type(); typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("configuration should be a pointer to a struct type")
+		return nil, fmt.Errorf("configuration should be a pointer to a struct type")
+	}
+
+	l := newLoader(opts)
+	if err := l.parseArguments(configuration); err != nil {
+		return l.report, err
+	}
+	for _, c := range l.commands {
+		l.setDefaults(c.configuration)
+	}
+	if opts.Data != nil {
+		if err := l.getFromData(opts.Data, opts.Format, SourceData, "", configuration); err != nil {
+			return l.report, fmt.Errorf("could not read configuration data: %v", err)
+		}
+	}
+	if err := l.getFromYAML(opts.Filename, configuration); err != nil && (opts.RequireFile || isDecryptError(err) || isChecksumError(err)) {
+		return l.report, fmt.Errorf("could not read configuration file %s: %v", redactURL(opts.Filename), err)
+	}
+	if err := l.getFromKV(configuration); err != nil {
+		return l.report, fmt.Errorf("could not read configuration from key/value store: %v", err)
+	}
+	if err := l.getFromArguments(); err != nil {
+		return l.report, err
+	}
+	for _, c := range l.commands {
+		if err := l.getFromEnvVariables(c.configuration); err != nil {
+			return l.report, err
+		}
+	}
+	if err := l.interpolate(configuration); err != nil {
+		return l.report, err
+	}
+	if err := l.inlineFiles(configuration); err != nil {
+		return l.report, err
 	}
 
-	setDefaults(configuration)
-	getFromYAML(filename, configuration)
-	getFromArguments(configuration)
-	getFromEnvVariables(configuration)
+	l.report.Provenance = l.provenance(configuration)
 
-	return
+	if validator, ok := configuration.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return l.report, err
+		}
+	}
+	return l.report, nil
+}
+
+// Validator is implemented by configurations checking their own values.
+// Load fails with the error returned by Validate once all sources are read.
+type Validator interface {
+	Validate() error
+}
+
+// loader carries the state of a single Load through the sources.
+type loader struct {
+	opts   Options
+	logger Logger
+	report *Report
+	// commands holds the configuration and the command line of the main
+	// program followed by those of the selected subcommands.
+	commands []*command
+	// origins holds the values read for each field path, in order
+	origins map[string][]Origin
+	// decrypted marks the field paths whose last value was decrypted
+	decrypted map[string]bool
+}
+
+func newLoader(opts Options) *loader {
+	l := &loader{opts: opts, logger: opts.Logger, report: &Report{}, origins: map[string][]Origin{}, decrypted: map[string]bool{}}
+	if l.logger == nil {
+		l.logger = stdLogger{}
+	}
+	if l.opts.Args == nil {
+		l.opts.Args = os.Args
+	}
+	if l.opts.LookupEnv == nil {
+		l.opts.LookupEnv = os.LookupEnv
+	}
+	if l.opts.FS == nil {
+		l.opts.FS = osFS{}
+	}
+	if l.opts.ValueFS == nil {
+		l.opts.ValueFS = osFS{}
+	}
+	return l
+}
+
+// MapEnv returns a LookupEnv function reading the variables from env.
+func MapEnv(env map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
 }
 
 func getProgramName() string {
+	return getProgramNameFromArgs(os.Args)
+}
+
+func getProgramNameFromArgs(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
 
-	splitArg := strings.Split(os.Args[0], "\\")
+	splitArg := strings.Split(args[0], "\\")
 	if len(splitArg) <= 1 {
-		splitArg = strings.Split(os.Args[0], "/")
+		splitArg = strings.Split(args[0], "/")
 	}
 	return strings.TrimSuffix(splitArg[len(splitArg)-1], ".exe")
 }
 
 func setDefaults(configuration interface{}) (err error) {
-	getFromEnvVariablesOrArguments(defaultTagName, getFromDefault, configuration)
-	return
+	return newLoader(Options{}).setDefaults(configuration)
 }
 
 func getFromYAML(filename string, configuration interface{}) (err error) {
+	return newLoader(Options{}).getFromYAML(filename, configuration)
+}
+
+func getFromArguments(configuration interface{}) {
+	l := newLoader(Options{})
+	if l.parseArguments(configuration) == nil {
+		l.getFromArguments()
+	}
+}
+
+func getFromEnvVariables(configuration interface{}) {
+	newLoader(Options{}).getFromEnvVariables(configuration)
+}
+
+func (l *loader) setDefaults(configuration interface{}) (err error) {
+	return l.getFromEnvVariablesOrArguments(SourceDefault, defaultTagName, getFromDefault, configuration)
+}
+
+func (l *loader) getFromYAML(filename string, configuration interface{}) (err error) {
 
 	if len(filename) == 0 {
 		return
 	}
 
-	file, err := os.Open(filename)
-	if err != nil {
-		log.Println("Could not open file : " + filename + " skipping reading config from YAML.")
-		return
+	// the password of a URL is kept out of the logs and the report
+	name := redactURL(filename)
+	var data []byte
+	if isURL(filename) {
+		data, err = l.readURL(filename)
+		if err != nil {
+			l.logger.Printf("Could not fetch : %s skipping reading config from YAML. %v", name, err)
+			return
+		}
+	} else {
+		var file fs.File
+		file, err = l.opts.FS.Open(filename)
+		if err != nil {
+			l.logger.Printf("Could not open file : %s skipping reading config from YAML.", name)
+			return
+		}
+		defer file.Close()
+		data, err = ioutil.ReadAll(file)
+		if err != nil {
+			l.logger.Printf("Could not read from file : %s skipping reading config from YAML.", name)
+			return
+		}
 	}
-	defer file.Close()
-	data, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Println("Could not read from file : " + filename + " skipping reading config from YAML.")
+	if err = l.verifyChecksum(data); err != nil {
+		l.logger.Printf("Could not verify file : %s skipping reading config from YAML. %v", name, err)
 		return
 	}
-	err = yaml.Unmarshal(data, &configuration)
+	err = l.getFromData(data, getFormat(filename), SourceFile, name, configuration)
 	if err != nil {
-		log.Println("Could not unmarschal from file : " + filename + " skipping extracting config from YAML.")
+		l.logger.Printf("Could not unmarschal from file : %s skipping extracting config from YAML.", name)
 		return
 	}
 
 	return
 }
 
-func getFromArguments(configuration interface{}) {
-	getFromEnvVariablesOrArguments(argTagName, getFromArg, configuration)
-}
+// getFromData puts the values of data into the passed interface. name is
+// the name of the file the data was read from, if any.
+func (l *loader) getFromData(data []byte, format Format, source Source, name string, configuration interface{}) error {
+	typ := reflect.TypeOf(configuration).Elem()
+
+	var document map[string]interface{}
+	isDocument := unmarshal(data, format, &document) == nil
+	decrypted, content, contentFormat := document, data, format
+	if isDocument && hasEncrypted(document) {
+		value, err := l.decrypt("", typ, document)
+		if err != nil {
+			return err
+		}
+		decrypted = value.(map[string]interface{})
+		if content, err = yaml.Marshal(decrypted); err != nil {
+			return err
+		}
+		contentFormat = FormatYAML
+	}
+	if err := unmarshal(content, contentFormat, configuration); err != nil {
+		return err
+	}
 
-func getFromEnvVariables(configuration interface{}) {
-	getFromEnvVariablesOrArguments(envTagName, getFromEnv, configuration)
+	if isDocument {
+		// the report shows the values as they were read, encrypted or not
+		lines := newLineFinder(data, format)
+		l.setByDocument("", typ, document, source, name, lines)
+		l.getAliasesFromYAML(decrypted, document, configuration, source, name, lines)
+	}
+	return nil
 }
 
-type getData func(reflect.StructField, string) string
+// getAliasesFromYAML fills the fields whose file key is missing from one of
+// their aliases and records every deprecated key found in the document. The
+// raw document holds the values as they were read, before decryption.
+func (l *loader) getAliasesFromYAML(document map[string]interface{}, raw map[string]interface{}, configuration interface{}, source Source, name string, lines *lineFinder) {
+	s := reflect.ValueOf(configuration).Elem()
+	typ := s.Type()
 
-func getFromDefault(p reflect.StructField, key string) string {
-	tagContent := p.Tag.Get(defaultTagName)
-	if len(tagContent) > 0 {
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		aliases := getAliases(p)
+		if len(aliases) == 0 && !isDeprecated(p) {
+			continue
+		}
+
+		key := getFileKey(p)
+		if foundKey, _, ok := lookupKey(document, key); ok {
+			if len(aliases) == 0 {
+				l.deprecated(p, source, foundKey)
+			}
+			continue
+		}
+
+		for _, alias := range aliases {
+			foundKey, value, ok := lookupKey(document, alias)
+			if !ok {
+				continue
+			}
+			f := s.Field(i)
+			if !f.CanSet() {
+				break
+			}
+			data, err := json.Marshal(value)
+			if err == nil {
+				err = json.Unmarshal(data, f.Addr().Interface())
+			}
+			if err != nil {
+				l.logger.Printf("Could not read deprecated key %s into field %s : %v", foundKey, p.Name, err)
+				break
+			}
+			l.deprecated(p, source, foundKey)
+			l.recordRead(p.Name, Origin{Source: source, Name: name, Line: lines.find([]string{foundKey}), Value: fmt.Sprint(raw[foundKey])}, raw[foundKey])
+			break
+		}
+	}
+}
+
+// getKey returns the key of a field in the source read with tagName.
+func getKey(p reflect.StructField, tagName string) string {
+	if tagContent := p.Tag.Get(tagName); len(tagContent) > 0 {
 		return tagContent
 	}
-	return ""
+	return p.Name
 }
 
-func getFromEnv(p reflect.StructField, key string) string {
-	return os.Getenv(key)
+// getFileKey returns the key a field is read from in a YAML file.
+func getFileKey(p reflect.StructField) string {
+	if name := strings.Split(p.Tag.Get("json"), ",")[0]; len(name) > 0 {
+		return name
+	}
+	return p.Name
 }
 
-func getFromArg(p reflect.StructField, key string) string {
-	for i := range os.Args {
-		if strings.HasPrefix(os.Args[i], ("--" + key + "=")) {
-			return os.Args[i][len(key)+3:]
-		} else if os.Args[i] == ("--" + key) {
-			if len(os.Args) > i+1 {
-				if strings.HasPrefix(os.Args[i+1], "-") {
-					return "true"
-				}
-				return os.Args[i+1]
-			}
-			return "true"
+// lookupKey finds key in document ignoring case, like the YAML unmarshaller does.
+func lookupKey(document map[string]interface{}, key string) (string, interface{}, bool) {
+	if value, ok := document[key]; ok {
+		return key, value, true
+	}
+	for k, value := range document {
+		if strings.EqualFold(k, key) {
+			return k, value, true
+		}
+	}
+	return "", nil, false
+}
+
+func getAliases(p reflect.StructField) []string {
+	var aliases []string
+	for _, alias := range strings.Split(p.Tag.Get(aliasTagName), ",") {
+		if alias = strings.TrimSpace(alias); len(alias) > 0 {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+func isDeprecated(p reflect.StructField) bool {
+	_, ok := p.Tag.Lookup(deprecatedTagName)
+	return ok
+}
+
+// deprecated records that the value of field p was read from a deprecated key.
+func (l *loader) deprecated(p reflect.StructField, source Source, key string) {
+	message := p.Tag.Get(deprecatedTagName)
+	if len(message) == 0 {
+		message = "use " + replacementKey(p, source) + " instead"
+	}
+	d := Deprecation{Field: p.Name, Source: source, Key: key, Message: message}
+	l.report.Deprecations = append(l.report.Deprecations, d)
+	l.logger.Printf("WARNING: %s", d)
+}
+
+// replacementKey returns the key field p is read from in source, which
+// replaces its deprecated keys.
+func replacementKey(p reflect.StructField, source Source) string {
+	switch source {
+	case SourceEnv:
+		return getKey(p, envTagName)
+	case SourceArg:
+		return "--" + getKey(p, argTagName)
+	}
+	return getFileKey(p)
+}
+
+func (l *loader) getFromArguments() error {
+	for _, c := range l.commands {
+		if err := l.getFromEnvVariablesOrArguments(SourceArg, argTagName, c.args.getFromArg, c.configuration); err != nil {
+			return err
+		}
+		positional, err := l.getFromPositionals(c)
+		if err != nil {
+			return err
+		}
+		l.report.Args = positional
+	}
+	return nil
+}
+
+func (l *loader) getFromEnvVariables(configuration interface{}) error {
+	return l.getFromEnvVariablesOrArguments(SourceEnv, envTagName, l.getFromEnv, configuration)
+}
+
+// getData returns the value a source holds for key and the name it was read
+// from, which is key unless the source derives other names from it.
+type getData func(p reflect.StructField, key string) (value, name string, err error)
+
+func getFromDefault(p reflect.StructField, key string) (string, string, error) {
+	tagContent := p.Tag.Get(defaultTagName)
+	if len(tagContent) > 0 {
+		return tagContent, key, nil
+	}
+	return "", key, nil
+}
+
+// getFromEnv returns the value of the environment variable key, or the
+// content of the file named by key_FILE if key is not set.
+func (l *loader) getFromEnv(p reflect.StructField, key string) (string, string, error) {
+	if value, _ := l.opts.LookupEnv(key); len(value) > 0 {
+		return value, key, nil
+	}
+	if filename, _ := l.opts.LookupEnv(key + fileEnvSuffix); len(filename) > 0 {
+		value, err := l.readValueFile(filename)
+		if err != nil {
+			return "", key + fileEnvSuffix, fmt.Errorf("could not read %s from file %s: %v", key, filename, err)
 		}
+		return value, key + fileEnvSuffix, nil
 	}
-	return ""
+	return "", key, nil
 }
 
-func getFromEnvVariablesOrArguments(tagName string, fnGetData getData, configuration interface{}) {
+// getFromEnvVariablesOrArguments sets the fields of configuration from the
+// values fnGetData returns. Values the field can not hold are skipped,
+// except on the command line where they are reported.
+func (l *loader) getFromEnvVariablesOrArguments(source Source, tagName string, fnGetData getData, configuration interface{}) error {
 	typ := reflect.TypeOf(configuration)
 	// if a pointer to a struct is passed, get the type of the dereferenced object
 	if typ.Kind() == reflect.Ptr {
@@ -133,12 +538,29 @@ func getFromEnvVariablesOrArguments(tagName string, fnGetData getData, configura
 		p := typ.Field(i)
 
 		// check if we've got a field name override for the environment
-		tagContent := p.Tag.Get(tagName)
-		value := ""
-		if len(tagContent) > 0 {
-			value = fnGetData(p, tagContent)
-		} else {
-			value = fnGetData(p, p.Name)
+		value, key, err := fnGetData(p, getKey(p, tagName))
+		if err != nil {
+			return err
+		}
+
+		// fall back to the deprecated keys of the field
+		if source != SourceDefault && !p.Anonymous {
+			aliases := getAliases(p)
+			if len(value) == 0 {
+				for _, alias := range aliases {
+					var name string
+					if value, name, err = fnGetData(p, alias); err != nil {
+						return err
+					}
+					if len(value) > 0 {
+						l.deprecated(p, source, alias)
+						key = name
+						break
+					}
+				}
+			} else if len(aliases) == 0 && isDeprecated(p) {
+				l.deprecated(p, source, key)
+			}
 		}
 
 		if !p.Anonymous && len(value) > 0 {
@@ -154,69 +576,98 @@ func getFromEnvVariablesOrArguments(tagName string, fnGetData getData, configura
 					// the use of unexported struct fields.
 
 					// change value
-					kind := f.Kind()
-					if kind == reflect.Int || kind == reflect.Int64 {
-						setStringToInt(f, value, 64)
-					} else if kind == reflect.Int32 {
-						setStringToInt(f, value, 32)
-					} else if kind == reflect.Int16 {
-						setStringToInt(f, value, 16)
-					} else if kind == reflect.Uint || kind == reflect.Uint64 {
-						setStringToUInt(f, value, 64)
-					} else if kind == reflect.Uint32 {
-						setStringToUInt(f, value, 32)
-					} else if kind == reflect.Uint16 {
-						setStringToUInt(f, value, 16)
-					} else if kind == reflect.Bool {
-						setStringToBool(f, value)
-					} else if kind == reflect.Float64 {
-						setStringToFloat(f, value, 64)
-					} else if kind == reflect.Float32 {
-						setStringToFloat(f, value, 32)
-					} else if kind == reflect.String {
-						f.SetString(value)
+					if err := setStringToValue(f, value); err == nil {
+						l.setBy(configuration, p, source, key, value)
+					} else if source == SourceArg {
+						return fmt.Errorf("invalid value %q for flag --%s: %v", mask(p, value), key, maskError(p, value, err))
 					}
-
 				}
 			}
 		}
 	}
+	return nil
+}
+
+func setStringToValue(f reflect.Value, value string) error {
+	kind := f.Kind()
+	if kind == reflect.Int || kind == reflect.Int64 {
+		return setStringToInt(f, value, 64)
+	} else if kind == reflect.Int32 {
+		return setStringToInt(f, value, 32)
+	} else if kind == reflect.Int16 {
+		return setStringToInt(f, value, 16)
+	} else if kind == reflect.Uint || kind == reflect.Uint64 {
+		return setStringToUInt(f, value, 64)
+	} else if kind == reflect.Uint32 {
+		return setStringToUInt(f, value, 32)
+	} else if kind == reflect.Uint16 {
+		return setStringToUInt(f, value, 16)
+	} else if kind == reflect.Bool {
+		return setStringToBool(f, value)
+	} else if kind == reflect.Float64 {
+		return setStringToFloat(f, value, 64)
+	} else if kind == reflect.Float32 {
+		return setStringToFloat(f, value, 32)
+	} else if kind == reflect.String {
+		f.SetString(value)
+		return nil
+	}
+	return fmt.Errorf("unsupported type %s", f.Type())
+}
+
+// isScalar tells if values of typ can be read from a string, like the
+// environment and the command line provide.
+func isScalar(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
 }
 
-func setStringToInt(f reflect.Value, value string, bitSize int) {
+func setStringToInt(f reflect.Value, value string, bitSize int) error {
 	convertedValue, err := strconv.ParseInt(value, 10, bitSize)
 
 	if err == nil {
-		if !f.OverflowInt(convertedValue) {
-			f.SetInt(convertedValue)
+		if f.OverflowInt(convertedValue) {
+			return fmt.Errorf("%d overflows %s", convertedValue, f.Type())
 		}
+		f.SetInt(convertedValue)
 	}
+	return err
 }
 
-func setStringToUInt(f reflect.Value, value string, bitSize int) {
+func setStringToUInt(f reflect.Value, value string, bitSize int) error {
 	convertedValue, err := strconv.ParseUint(value, 10, bitSize)
 
 	if err == nil {
-		if !f.OverflowUint(convertedValue) {
-			f.SetUint(convertedValue)
+		if f.OverflowUint(convertedValue) {
+			return fmt.Errorf("%d overflows %s", convertedValue, f.Type())
 		}
+		f.SetUint(convertedValue)
 	}
+	return err
 }
 
-func setStringToBool(f reflect.Value, value string) {
+func setStringToBool(f reflect.Value, value string) error {
 	convertedValue, err := strconv.ParseBool(value)
 
 	if err == nil {
 		f.SetBool(convertedValue)
 	}
+	return err
 }
 
-func setStringToFloat(f reflect.Value, value string, bitSize int) {
+func setStringToFloat(f reflect.Value, value string, bitSize int) error {
 	convertedValue, err := strconv.ParseFloat(value, bitSize)
 
 	if err == nil {
-		if !f.OverflowFloat(convertedValue) {
-			f.SetFloat(convertedValue)
+		if f.OverflowFloat(convertedValue) {
+			return fmt.Errorf("%g overflows %s", convertedValue, f.Type())
 		}
+		f.SetFloat(convertedValue)
 	}
+	return err
 }
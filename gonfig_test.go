@@ -1,6 +1,7 @@
 package gonfig
 
 import (
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -772,3 +773,173 @@ func Test_Overrides(t *testing.T) {
 		t.Error("TestString should be fromENV", conf.TestString)
 	}
 }
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func Test_Load_should_honor_deprecated_env_alias(t *testing.T) {
+	type Conf struct {
+		DBHost string `env:"DB_HOST" alias:"DATABASE_HOST" deprecated:"use DB_HOST instead"`
+	}
+	args := []string{"cmd"}
+	env := MapEnv(map[string]string{"DATABASE_HOST": "db.local"})
+
+	logger := &recordingLogger{}
+	conf := Conf{}
+	report, err := Load(&conf, Options{Args: args, LookupEnv: env, Logger: logger})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.DBHost != "db.local" {
+		t.Error("DBHost should be db.local", conf.DBHost)
+	}
+	if len(report.Deprecations) != 1 {
+		t.Fatal("there should be one deprecation", report.Deprecations)
+	}
+	d := report.Deprecations[0]
+	if d.Field != "DBHost" || d.Source != SourceEnv || d.Key != "DATABASE_HOST" || d.Message != "use DB_HOST instead" {
+		t.Error("unexpected deprecation", d)
+	}
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "DATABASE_HOST") {
+		t.Error("the deprecation should be logged", logger.lines)
+	}
+}
+
+func Test_Load_should_name_the_replacing_key_of_the_source(t *testing.T) {
+	type Conf struct {
+		DBHost string `env:"DB_HOST" arg:"db-host" alias:"DATABASE_HOST,database-host"`
+	}
+	env := MapEnv(map[string]string{"DATABASE_HOST": "db.local"})
+
+	conf := Conf{}
+	report, _ := Load(&conf, Options{Args: []string{"cmd"}, LookupEnv: env, Logger: &recordingLogger{}})
+	if len(report.Deprecations) != 1 || report.Deprecations[0].Message != "use DB_HOST instead" {
+		t.Error("the variable should be named", report.Deprecations)
+	}
+
+	conf = Conf{}
+	report, _ = Load(&conf, Options{Args: []string{"cmd", "--database-host=db.local"}, LookupEnv: MapEnv(nil), Logger: &recordingLogger{}})
+	if len(report.Deprecations) != 1 || report.Deprecations[0].Message != "use --db-host instead" {
+		t.Error("the flag should be named", report.Deprecations)
+	}
+}
+
+func Test_Load_should_prefer_new_key_over_deprecated_alias(t *testing.T) {
+	type Conf struct {
+		DBHost string `arg:"db-host" alias:"database-host"`
+	}
+	args := []string{"cmd", "--database-host=old", "--db-host=new"}
+
+	conf := Conf{}
+	report, _ := Load(&conf, Options{Args: args, Logger: &recordingLogger{}})
+
+	if conf.DBHost != "new" {
+		t.Error("DBHost should be new", conf.DBHost)
+	}
+	if len(report.Deprecations) != 0 {
+		t.Error("there should be no deprecation", report.Deprecations)
+	}
+}
+
+func Test_Load_should_honor_deprecated_file_alias(t *testing.T) {
+
+	filename := tmpFileWithContent("database_host: db.local\nPort: 8080", t)
+	args := []string{"cmd"}
+	defer func() {
+		os.Remove(filename)
+	}()
+
+	type Conf struct {
+		DBHost string `alias:"database_host"`
+		Port   int
+	}
+	conf := Conf{}
+	report, _ := Load(&conf, Options{Args: args, Filename: filename, Logger: &recordingLogger{}})
+
+	if conf.DBHost != "db.local" {
+		t.Error("DBHost should be db.local", conf.DBHost)
+	}
+	if conf.Port != 8080 {
+		t.Error("Port should be 8080", conf.Port)
+	}
+	if len(report.Deprecations) != 1 {
+		t.Fatal("there should be one deprecation", report.Deprecations)
+	}
+	d := report.Deprecations[0]
+	if d.Source != SourceFile || d.Key != "database_host" || d.Message != "use DBHost instead" {
+		t.Error("unexpected deprecation", d)
+	}
+}
+
+func Test_Load_should_report_deprecated_field(t *testing.T) {
+
+	filename := tmpFileWithContent("Legacy: 1", t)
+	args := []string{"cmd"}
+	defer func() {
+		os.Remove(filename)
+	}()
+
+	type Conf struct {
+		Legacy int `deprecated:"Legacy is ignored since v2"`
+	}
+	conf := Conf{}
+	report, _ := Load(&conf, Options{Args: args, Filename: filename, Logger: &recordingLogger{}})
+
+	if conf.Legacy != 1 {
+		t.Error("Legacy should still be read", conf.Legacy)
+	}
+	if len(report.Deprecations) != 1 || report.Deprecations[0].Key != "Legacy" {
+		t.Error("the deprecated field should be reported", report.Deprecations)
+	}
+}
+
+func Test_Load_should_read_injected_args_and_env(t *testing.T) {
+	t.Parallel()
+
+	type Conf struct {
+		ID         int    `default:"1"`
+		TestString string `default:"fromDefault"`
+		Other      string `env:"OTHER"`
+	}
+	conf := Conf{}
+	_, err := Load(&conf, Options{
+		Args:      []string{"cmd", "--ID=3"},
+		LookupEnv: MapEnv(map[string]string{"TestString": "fromENV", "OTHER": "other"}),
+	})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.ID != 3 {
+		t.Error("ID should be 3", conf.ID)
+	}
+	if conf.TestString != "fromENV" {
+		t.Error("TestString should be fromENV", conf.TestString)
+	}
+	if conf.Other != "other" {
+		t.Error("Other should be other", conf.Other)
+	}
+}
+
+func Test_Load_should_not_read_process_args_if_injected(t *testing.T) {
+	t.Parallel()
+
+	type Conf struct {
+		ID int
+	}
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: []string{}, LookupEnv: MapEnv(nil)})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.ID != 0 {
+		t.Error("ID should be 0", conf.ID)
+	}
+}
@@ -0,0 +1,110 @@
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+)
+
+// ErrHelp is returned by Load when the command line asks for help with
+// --help or -h. The usage has been printed by then. With AllowUnknownFlags
+// the help flags are left to the caller, which Report.Help tells.
+var ErrHelp = errors.New("gonfig: help requested")
+
+// help flags, only recognized if the configuration doesn't declare them itself
+const helpFlag = "help"
+const helpShortFlag = "h"
+
+// WriteUsage writes the usage Load prints on --help for configuration, a
+// pointer to a struct, to w. For a subcommand, pass its configuration and
+// the program name followed by the name of the command.
+func WriteUsage(w io.Writer, program string, configuration interface{}) error {
+	typ := reflect.TypeOf(configuration)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("configuration should be a pointer to a struct type")
+	}
+	specs, err := getFlagSpecs(typ.Elem())
+	if err != nil {
+		return err
+	}
+	return printUsage(w, program, typ.Elem(), specs)
+}
+
+// printUsage writes the flags, environment variables, types, defaults and
+// descriptions of the fields of typ to w.
+func printUsage(w io.Writer, program string, typ reflect.Type, specs flagSpecs) error {
+	infos := getFieldInfos(typ)
+
+	usage := "Usage: " + program + " [flags]"
+	if len(specs.commands) > 0 {
+		usage += " <command> [command flags]"
+	}
+	for _, index := range sortedPositions(specs) {
+		usage += " <" + typ.Field(specs.positionals[index]).Name + ">"
+	}
+	if specs.rest >= 0 {
+		usage += " [" + typ.Field(specs.rest).Name + "...]"
+	}
+	fmt.Fprintf(w, "%s\n\n", usage)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  FLAG\tENV\tTYPE\tDEFAULT\tDESCRIPTION")
+	for _, info := range infos {
+		if len(info.position) == 0 && isScalar(info.field.Type) {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", formatFlag(info.short, info.flag), info.env, info.typeName(), info.def, info.description)
+		}
+	}
+
+	short := ""
+	if specs.short[helpShortFlag] == nil {
+		short = helpShortFlag
+	}
+	if specs.long[helpFlag] == nil {
+		fmt.Fprintf(tw, "  %s\t\t\t\t%s\n", formatFlag(short, helpFlag), "show this help")
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(specs.positionals) > 0 || specs.rest >= 0 {
+		fmt.Fprintf(w, "\nArguments:\n")
+		for _, info := range infos {
+			if len(info.position) > 0 {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", info.field.Name, info.typeName(), info.description)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(specs.commands) > 0 {
+		fmt.Fprintf(w, "\nCommands:\n")
+		for i := 0; i < typ.NumField(); i++ {
+			if p := typ.Field(i); isCommand(p) && len(p.PkgPath) == 0 {
+				fmt.Fprintf(tw, "  %s\t%s\n", p.Tag.Get(cmdTagName), p.Tag.Get(descriptionTagName))
+			}
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+func formatFlag(short, long string) string {
+	if len(short) > 0 {
+		return "-" + short + ", --" + long
+	}
+	return "    --" + long
+}
+
+func sortedPositions(specs flagSpecs) []int {
+	positions := make([]int, 0, len(specs.positionals))
+	for index := range specs.positionals {
+		positions = append(positions, index)
+	}
+	sort.Ints(positions)
+	return positions
+}
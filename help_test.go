@@ -0,0 +1,93 @@
+package gonfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_Load_should_print_usage_on_help(t *testing.T) {
+	type Conf struct {
+		Port     int    `arg:"port" short:"p" env:"PORT" default:"8080" description:"port to listen on"`
+		Verbose  bool   `description:"log more"`
+		Name     string `env:"APP_NAME"`
+		Database struct {
+			Host string
+		}
+	}
+	args := []string{"cmd", "--typo", "--help"}
+
+	output := &bytes.Buffer{}
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: args, HelpOutput: output})
+
+	if err != ErrHelp {
+		t.Error("Load should return ErrHelp", err)
+	}
+	usage := output.String()
+	for _, expected := range []string{
+		"-p, --port PORT int 8080 port to listen on",
+		"--Verbose Verbose bool log more",
+		"--Name APP_NAME string",
+		"-h, --help show this help",
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(usage), " "), expected) {
+			t.Errorf("usage should contain %q but is :\n%s", expected, usage)
+		}
+	}
+	if strings.Contains(usage, "Database") {
+		t.Error("usage should not list nested structs", usage)
+	}
+}
+
+func Test_Load_should_leave_help_to_the_caller_with_unknown_flags(t *testing.T) {
+	type Conf struct {
+		Port int
+	}
+	args := []string{"cmd", "--help", "--Port=80"}
+
+	output := &bytes.Buffer{}
+	conf := Conf{}
+	report, err := Load(&conf, Options{Args: args, HelpOutput: output, AllowUnknownFlags: true})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.Port != 80 {
+		t.Error("Port should be 80", conf.Port)
+	}
+	if output.Len() != 0 {
+		t.Error("usage should not be printed", output.String())
+	}
+	if !report.Help {
+		t.Error("the report should tell help was asked for")
+	}
+
+	if err := WriteUsage(output, "cmd", &conf); err != nil {
+		t.Fatal("WriteUsage unexpected error occured", err)
+	}
+	if usage := output.String(); !strings.HasPrefix(usage, "Usage: cmd [flags]") || !strings.Contains(usage, "--Port") {
+		t.Error("unexpected usage", usage)
+	}
+}
+
+func Test_Load_should_not_reserve_declared_help_flags(t *testing.T) {
+	type Conf struct {
+		Host string `short:"h"`
+	}
+	args := []string{"cmd", "-h", "localhost"}
+
+	output := &bytes.Buffer{}
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: args, HelpOutput: output})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.Host != "localhost" {
+		t.Error("Host should be localhost", conf.Host)
+	}
+	if output.Len() != 0 {
+		t.Error("usage should not be printed", output.String())
+	}
+}
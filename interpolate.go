@@ -0,0 +1,193 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// interpolator expands the ${...} references in the string fields, and in the
+// strings of slice and map fields, read from files, configuration data,
+// key/value stores and default tags. Values given on the command line or in
+// the environment are taken literally.
+//
+//	${VAR}            the environment variable VAR, empty if it isn't set
+//	${VAR:-fallback}  fallback if VAR is empty or not set
+//	${.Field.Path}    the final value of another field, expanded first
+//	$${               a literal ${
+//
+// Secrets can only be referenced from other secrets, so their values are
+// never shown unmasked.
+type interpolator struct {
+	l      *loader
+	leaves map[string]reflect.Value
+	fields map[string]reflect.StructField
+	// done marks the expanded fields, false while a field is being expanded
+	done  map[string]bool
+	stack []string
+}
+
+func (l *loader) interpolate(configuration interface{}) error {
+	in := &interpolator{l: l, leaves: map[string]reflect.Value{}, fields: map[string]reflect.StructField{}, done: map[string]bool{}}
+	var paths []string
+	walkLeaves("", reflect.ValueOf(configuration).Elem(), func(path string, p reflect.StructField, v reflect.Value) {
+		in.leaves[path] = v
+		in.fields[path] = p
+		paths = append(paths, path)
+	})
+
+	for _, path := range paths {
+		if in.expandable(path) {
+			if err := in.resolve(path); err != nil {
+				return fmt.Errorf("could not interpolate field %s: %v", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// expandable tells if the field at path holds strings and its final value
+// was read from a file, configuration data, a key/value store or a default
+// tag. Decrypted values are taken literally.
+func (in *interpolator) expandable(path string) bool {
+	origins := in.l.origins[path]
+	if !holdsStrings(in.leaves[path].Type()) || len(origins) == 0 || in.l.decrypted[path] {
+		return false
+	}
+	switch origins[len(origins)-1].Source {
+	case SourceDefault, SourceData, SourceFile, SourceKV:
+		return true
+	}
+	return false
+}
+
+func (in *interpolator) resolve(path string) error {
+	if done, seen := in.done[path]; seen {
+		if !done {
+			return fmt.Errorf("reference cycle %s -> %s", strings.Join(in.stack, " -> "), path)
+		}
+		return nil
+	}
+	in.done[path] = false
+	in.stack = append(in.stack, path)
+
+	if err := in.expandValue(in.leaves[path]); err != nil {
+		return err
+	}
+
+	in.done[path] = true
+	in.stack = in.stack[:len(in.stack)-1]
+	return nil
+}
+
+// holdsStrings tells if typ is a string or a slice, array or map of them.
+func holdsStrings(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.String
+}
+
+// expandValue expands the references in v, a string or a slice, array or map
+// holding them.
+func (in *interpolator) expandValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		expanded, err := in.expand(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(expanded)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := in.expandValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values can't be set in place
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			if err := in.expandValue(value); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), value)
+		}
+	}
+	return nil
+}
+
+func (in *interpolator) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(s[i+2:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference at offset %d", i)
+		}
+		value, err := in.lookup(s[i+2 : i+2+end])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+		i += end + 3
+	}
+	return b.String(), nil
+}
+
+// lookup returns the value of the reference ref, the text between ${ and }.
+func (in *interpolator) lookup(ref string) (string, error) {
+	name, fallback, hasFallback := cut(ref, ":-")
+
+	var value string
+	if strings.HasPrefix(name, ".") {
+		path := name[1:]
+		v, ok := in.leaves[path]
+		if !ok {
+			return "", fmt.Errorf("unknown field %s in ${%s}", path, ref)
+		}
+		if from := in.stack[len(in.stack)-1]; isSecret(in.fields[path]) && !isSecret(in.fields[from]) {
+			return "", fmt.Errorf("secret field %s can't be referenced from %s, which isn't secret", path, from)
+		}
+		if in.expandable(path) {
+			if err := in.resolve(path); err != nil {
+				return "", err
+			}
+		}
+		value = formatValue(v)
+	} else {
+		value, _ = in.l.opts.LookupEnv(name)
+	}
+
+	if len(value) == 0 && hasFallback {
+		return fallback, nil
+	}
+	return value, nil
+}
+
+// formatValue returns the value of v as it would be written in a file. The
+// value of a Secret is not masked.
+func formatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,217 @@
+package gonfig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// KV is a key/value store like Consul or etcd holding configuration values.
+type KV interface {
+	// List returns every key starting with prefix together with its value.
+	List(prefix string) (map[string]string, error)
+}
+
+// getFromKV reads the keys under opts.KVPrefix into configuration. The last
+// part of a key names a field, the parts before it nested structs or maps.
+// A value is read like a YAML value unless its field is a string, so numbers,
+// booleans and JSON documents for nested structs can be stored.
+func (l *loader) getFromKV(configuration interface{}) error {
+	if l.opts.KV == nil {
+		return nil
+	}
+	pairs, err := l.opts.KV.List(l.opts.KVPrefix)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	typ := reflect.TypeOf(configuration).Elem()
+	document := map[string]interface{}{}
+	paths := map[string]string{}
+	for _, key := range keys {
+		relative, ok := relativeKey(key, l.opts.KVPrefix)
+		if !ok || len(relative) == 0 {
+			continue
+		}
+		parts := strings.Split(relative, "/")
+
+		node, nodeType, path := document, reflect.Type(typ), ""
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[part] = child
+			}
+			path = fieldPath(nodeType, path, part)
+			node, nodeType = child, fieldType(nodeType, part)
+		}
+
+		last := parts[len(parts)-1]
+		if node[last], err = kvValue(fieldType(nodeType, last), pairs[key]); err != nil {
+			return fmt.Errorf("invalid value of key %s: %v", key, err)
+		}
+		if path = fieldPath(nodeType, path, last); len(path) > 0 {
+			paths[key] = path
+		}
+	}
+
+	if hasEncrypted(document) {
+		value, err := l.decrypt("", typ, document)
+		if err != nil {
+			return err
+		}
+		document = value.(map[string]interface{})
+	}
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return err
+	}
+	if err := unmarshal(data, FormatYAML, configuration); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if path, ok := paths[key]; ok {
+			l.recordRead(path, Origin{Source: SourceKV, Name: key, Value: pairs[key]}, pairs[key])
+		}
+	}
+	return nil
+}
+
+// relativeKey returns key without prefix. The prefix only matches whole
+// parts of the key, so "app" doesn't match "application/x".
+func relativeKey(key, prefix string) (string, bool) {
+	key, prefix = strings.Trim(key, "/"), strings.Trim(prefix, "/")
+	if len(prefix) == 0 || key == prefix {
+		return strings.TrimPrefix(key, prefix), true
+	}
+	if !strings.HasPrefix(key, prefix+"/") {
+		return "", false
+	}
+	return strings.Trim(key[len(prefix)+1:], "/"), true
+}
+
+// fieldPath returns the path of the field of typ read from key, appended to
+// the path of typ. It is empty if key isn't read into a struct field.
+func fieldPath(typ reflect.Type, path string, key string) string {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return ""
+	}
+	_, name, ok := lookupField(typ, key)
+	if !ok {
+		return ""
+	}
+	if len(path) > 0 {
+		return path + "." + name
+	}
+	return name
+}
+
+func kvValue(typ reflect.Type, value string) (interface{}, error) {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() == reflect.String || isEncrypted(value) {
+		return value, nil
+	}
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// MapKV is a KV holding its keys in memory, for tests and local development.
+type MapKV map[string]string
+
+func (kv MapKV) List(prefix string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for key, value := range kv {
+		if strings.HasPrefix(key, prefix) {
+			pairs[key] = value
+		}
+	}
+	return pairs, nil
+}
+
+// ConsulKV reads keys from the HTTP API of a Consul agent.
+type ConsulKV struct {
+	// Address is the URL of the agent, like http://127.0.0.1:8500.
+	Address string
+	// Token is sent as X-Consul-Token if it is set.
+	Token string
+	// Client sends the requests, a client with a 10 second timeout is used
+	// if it is nil.
+	Client *http.Client
+}
+
+var defaultKVClient = &http.Client{Timeout: 10 * time.Second}
+
+func (c *ConsulKV) List(prefix string) (map[string]string, error) {
+	client := c.Client
+	if client == nil {
+		client = defaultKVClient
+	}
+
+	parts := strings.Split(prefix, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	request, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(c.Address, "/")+"/v1/kv/"+strings.Join(parts, "/")+"?recurse=true", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Token) > 0 {
+		request.Header.Set("X-Consul-Token", c.Token)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	pairs := map[string]string{}
+	if response.StatusCode == http.StatusNotFound {
+		return pairs, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul responded %s", response.Status)
+	}
+
+	var entries []struct {
+		Key   string
+		Value *string
+	}
+	if err := json.NewDecoder(response.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		// folders have no value
+		if entry.Value == nil {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(*entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of key %s: %v", entry.Key, err)
+		}
+		pairs[entry.Key] = string(value)
+	}
+	return pairs, nil
+}
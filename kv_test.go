@@ -0,0 +1,130 @@
+package gonfig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type kvConf struct {
+	Name     string `default:"app"`
+	Pin      string
+	Port     int
+	Debug    bool `env:"DEBUG"`
+	Database struct {
+		Host  string
+		Ports []int
+	}
+	Labels map[string]string
+}
+
+func Test_Load_should_read_kv_between_file_and_env(t *testing.T) {
+	fsys := fstest.MapFS{"config.yaml": &fstest.MapFile{Data: []byte("Port: 80\nDebug: true\nDatabase:\n  Host: file")}}
+	kv := MapKV{
+		"app/port":            "8080",
+		"app/pin":             "0123",
+		"app/debug":           "false",
+		"app/database/host":   "db.internal",
+		"app/database/ports":  "[5432, 5433]",
+		"app/labels/team":     "core",
+		"other/port":          "1",
+		"app/":                "",
+		"app/unknown/setting": "x",
+	}
+	env := MapEnv(map[string]string{"DEBUG": "true"})
+
+	conf := kvConf{}
+	report, err := Load(&conf, Options{Filename: "config.yaml", FS: fsys, KV: kv, KVPrefix: "app/", LookupEnv: env, Args: []string{"app"}})
+
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+	if conf.Port != 8080 || conf.Pin != "0123" || conf.Name != "app" {
+		t.Error("the kv values should override the file", conf)
+	}
+	if !conf.Debug {
+		t.Error("env should override the kv", conf.Debug)
+	}
+	if conf.Database.Host != "db.internal" || !reflect.DeepEqual(conf.Database.Ports, []int{5432, 5433}) {
+		t.Error("nested keys should be read into nested structs", conf.Database)
+	}
+	if conf.Labels["team"] != "core" {
+		t.Error("nested keys should be read into maps", conf.Labels)
+	}
+
+	p, _ := report.Lookup("Database.Host")
+	if p.Origin != (Origin{Source: SourceKV, Name: "app/database/host", Value: "db.internal"}) || len(p.Overridden) != 1 {
+		t.Error("Database.Host should come from the kv", p)
+	}
+}
+
+func Test_Load_should_match_kv_prefix_by_whole_parts(t *testing.T) {
+	kv := MapKV{
+		"app/port":          "8080",
+		"appport":           "1",
+		"apppin":            "0123",
+		"app/database/host": "db.internal",
+	}
+
+	conf := kvConf{}
+	_, err := Load(&conf, Options{KV: kv, KVPrefix: "app", Args: []string{"app"}})
+
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+	if conf.Port != 8080 || conf.Pin != "" || conf.Database.Host != "db.internal" {
+		t.Error("only the keys under app/ should be read", conf)
+	}
+}
+
+type failingKV struct{}
+
+func (failingKV) List(prefix string) (map[string]string, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func Test_Load_should_fail_on_unreachable_kv(t *testing.T) {
+	conf := kvConf{}
+	_, err := Load(&conf, Options{KV: failingKV{}, Args: []string{"app"}})
+
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Error("Load should fail if the kv can't be read", err)
+	}
+}
+
+func Test_ConsulKV_should_list_keys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/app/" || r.URL.Query().Get("recurse") != "true" || r.Header.Get("X-Consul-Token") != "secret" {
+			t.Error("unexpected request", r.URL, r.Header)
+		}
+		encode := base64.StdEncoding.EncodeToString
+		fmt.Fprintf(w, `[{"Key":"app/","Value":null},{"Key":"app/port","Value":%q},{"Key":"app/database/host","Value":%q}]`, encode([]byte("8080")), encode([]byte("db")))
+	}))
+	defer server.Close()
+
+	kv := &ConsulKV{Address: server.URL, Token: "secret"}
+	pairs, err := kv.List("app/")
+
+	if err != nil {
+		t.Fatal("List unexpected error occured", err)
+	}
+	if !reflect.DeepEqual(pairs, map[string]string{"app/port": "8080", "app/database/host": "db"}) {
+		t.Error("unexpected keys", pairs)
+	}
+}
+
+func Test_ConsulKV_should_return_no_keys_for_missing_prefix(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	pairs, err := (&ConsulKV{Address: server.URL}).List("missing/")
+
+	if err != nil || len(pairs) != 0 {
+		t.Error("a missing prefix should have no keys", pairs, err)
+	}
+}
@@ -0,0 +1,201 @@
+package gonfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MarshalOptions controls how Marshal writes a configuration.
+type MarshalOptions struct {
+	// OmitDefaults leaves out the fields holding the value of their default
+	// tag, or their zero value if they have none or it isn't read.
+	OmitDefaults bool
+}
+
+// Marshal writes the merged configuration in format, with the keys it is
+// read from and the values of secrets that are set masked. Fields are written in the
+// order of the struct, nil pointers are left out.
+func Marshal(configuration interface{}, format Format) ([]byte, error) {
+	return MarshalOptions{}.Marshal(configuration, format)
+}
+
+// Marshal is like the Marshal function, configured by o.
+func (o MarshalOptions) Marshal(configuration interface{}, format Format) ([]byte, error) {
+	v := reflect.ValueOf(configuration)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("configuration should be a struct or a pointer to a struct type")
+	}
+	values := o.object(v, true)
+
+	var b bytes.Buffer
+	switch format {
+	case FormatYAML, "":
+		if err := writeYAML(&b, values, ""); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return b.Bytes(), nil
+}
+
+// keyValue is a key of an object with its value, which is an object itself
+// for nested structs.
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+// object holds the values of a struct in the order of its fields.
+type object []keyValue
+
+func (o object) MarshalJSON() ([]byte, error) {
+	return marshalObject(len(o), func(i int) (string, interface{}) {
+		return o[i].key, o[i].value
+	})
+}
+
+// String writes o like fmt writes a struct with %+v, for the masked values
+// of the report and of Diff.
+func (o object) String() string {
+	parts := make([]string, len(o))
+	for i, kv := range o {
+		parts[i] = fmt.Sprintf("%s:%v", kv.key, kv.value)
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// object returns the values of the struct v. defaults tells if the default
+// tags of its fields are read, which is only the case for the main
+// configuration and the ones of subcommands.
+func (o MarshalOptions) object(v reflect.Value, defaults bool) object {
+	values := object{}
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		key := getFileKey(p)
+		if len(p.PkgPath) > 0 || key == "-" {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Ptr && f.IsNil() {
+			continue
+		}
+
+		if _, ok := structType(p.Type); ok && !isSecret(p) {
+			nested := o.object(reflect.Indirect(f), isCommand(p))
+			if p.Anonymous {
+				values = append(values, nested...)
+			} else if len(nested) > 0 || !o.OmitDefaults {
+				values = append(values, keyValue{key: key, value: nested})
+			}
+			continue
+		}
+
+		if o.OmitDefaults && isDefault(p, f, defaults) {
+			continue
+		}
+		var value interface{} = redacted
+		if !isSecret(p) || f.IsZero() {
+			value = masked(f)
+		}
+		values = append(values, keyValue{key: key, value: value})
+	}
+	return values
+}
+
+// masked returns the value of v with the secrets held by the values of its
+// slices, arrays and maps masked. Structs in them are written like nested
+// structs.
+func masked(v reflect.Value) interface{} {
+	if v.Type() == secretType {
+		if v.Len() > 0 {
+			return redacted
+		}
+		return v.Interface()
+	}
+	if !holdsSecret(v.Type(), nil) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return masked(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = masked(v.Index(i))
+		}
+		return values
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		values := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values[fmt.Sprint(iter.Key().Interface())] = masked(iter.Value())
+		}
+		return values
+	case reflect.Struct:
+		return MarshalOptions{}.object(v, false)
+	}
+	return v.Interface()
+}
+
+// isDefault tells if f, the value of field p, holds the value of the default
+// tag of p if defaults are read, or the zero value.
+func isDefault(p reflect.StructField, f reflect.Value, defaults bool) bool {
+	def := reflect.New(f.Type()).Elem()
+	if tag := p.Tag.Get(defaultTagName); len(tag) > 0 && defaults {
+		if setStringToValue(def, tag) != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(f.Interface(), def.Interface())
+}
+
+// writeYAML writes values as YAML blocks. Values other than nested objects
+// are written in the JSON syntax, which YAML reads as well.
+func writeYAML(b *bytes.Buffer, values object, indent string) error {
+	for _, kv := range values {
+		key := kv.key
+		if strings.ContainsAny(key, ":#{}[],&*!|>'\"%@`") || strings.TrimSpace(key) != key {
+			quoted, _ := json.Marshal(key)
+			key = string(quoted)
+		}
+
+		if nested, ok := kv.value.(object); ok && len(nested) > 0 {
+			fmt.Fprintf(b, "%s%s:\n", indent, key)
+			if err := writeYAML(b, nested, indent+"  "); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := json.Marshal(kv.value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s%s: %s\n", indent, key, value)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package gonfig
+
+import (
+	"testing"
+	"time"
+)
+
+type marshalConf struct {
+	Port     int    `default:"8080"`
+	Host     string `json:"host"`
+	Password Secret
+	Token    string `secret:"true"`
+	Tags     []string
+	Started  time.Time
+	Database struct {
+		Host string
+		Pool int
+	}
+	Serve  *serveConf `cmd:"serve"`
+	hidden string
+}
+
+func Test_Marshal_should_write_yaml(t *testing.T) {
+	conf := marshalConf{Port: 80, Host: "example.com", Password: "hunter2", Token: "abc", Tags: []string{"a", "b"}}
+	conf.Database.Host = "db"
+
+	data, err := Marshal(&conf, FormatYAML)
+	if err != nil {
+		t.Fatal("Marshal unexpected error occured", err)
+	}
+
+	expected := `Port: 80
+host: "example.com"
+Password: "******"
+Token: "******"
+Tags: ["a","b"]
+Started: "0001-01-01T00:00:00Z"
+Database:
+  Host: "db"
+  Pool: 0
+`
+	if string(data) != expected {
+		t.Error("unexpected yaml", string(data))
+	}
+
+	read := marshalConf{}
+	if err := unmarshal(data, FormatYAML, &read); err != nil || read.Host != "example.com" || read.Database.Host != "db" || len(read.Tags) != 2 {
+		t.Error("the yaml should be readable", read, err)
+	}
+}
+
+func Test_Marshal_should_omit_defaults(t *testing.T) {
+	conf := marshalConf{Port: 8080, Host: "example.com", Serve: &serveConf{Port: 80}}
+
+	data, err := MarshalOptions{OmitDefaults: true}.Marshal(conf, FormatJSON)
+	if err != nil {
+		t.Fatal("Marshal unexpected error occured", err)
+	}
+
+	expected := "{\n  \"host\": \"example.com\",\n  \"Serve\": {\n    \"Port\": 80\n  }\n}\n"
+	if string(data) != expected {
+		t.Error("unexpected json", string(data))
+	}
+}
+
+func Test_Marshal_should_mask_secrets_in_containers(t *testing.T) {
+	type User struct {
+		Name     string
+		Password Secret
+		Token    string `secret:"true"`
+	}
+	type Conf struct {
+		Users    []User
+		Accounts map[string]*User
+		Keys     map[string][]Secret
+	}
+	conf := Conf{
+		Users:    []User{{Name: "admin", Password: "hunter2", Token: "abc"}},
+		Accounts: map[string]*User{"root": {Name: "root", Password: "toor"}},
+		Keys:     map[string][]Secret{"api": {"xyz"}},
+	}
+
+	data, err := Marshal(&conf, FormatJSON)
+	if err != nil {
+		t.Fatal("Marshal unexpected error occured", err)
+	}
+
+	expected := `{
+  "Users": [
+    {
+      "Name": "admin",
+      "Password": "******",
+      "Token": "******"
+    }
+  ],
+  "Accounts": {
+    "root": {
+      "Name": "root",
+      "Password": "******",
+      "Token": ""
+    }
+  },
+  "Keys": "******"
+}
+`
+	if string(data) != expected {
+		t.Errorf("unexpected JSON:\n%s", data)
+	}
+}
+
+func Test_Marshal_should_fail_on_unknown_format(t *testing.T) {
+	if _, err := Marshal(marshalConf{}, FormatTOML); err == nil {
+		t.Error("Marshal should fail on formats it can't write")
+	}
+	if _, err := Marshal(42, FormatYAML); err == nil {
+		t.Error("Marshal should fail on values that are not structs")
+	}
+}
@@ -0,0 +1,87 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tag name to bind a positional command-line argument to a field
+const posTagName = "pos"
+
+// value of the pos tag binding the remaining positional arguments to a slice
+const posRest = "rest"
+
+func isPositional(p reflect.StructField) bool {
+	return len(p.Tag.Get(posTagName)) > 0
+}
+
+// addPositional records the positional argument bound to field i of typ.
+func (specs *flagSpecs) addPositional(typ reflect.Type, i int) error {
+	p := typ.Field(i)
+	pos := p.Tag.Get(posTagName)
+
+	if pos == posRest {
+		if p.Type.Kind() != reflect.Slice {
+			return fmt.Errorf("field %s should be a slice to hold the rest of the positional arguments", p.Name)
+		}
+		if specs.rest >= 0 {
+			return fmt.Errorf("fields %s and %s both hold the rest of the positional arguments", typ.Field(specs.rest).Name, p.Name)
+		}
+		specs.rest = i
+		return nil
+	}
+
+	index, err := strconv.Atoi(pos)
+	if err != nil || index < 0 {
+		return fmt.Errorf("position of field %s should be a positive number or %q: %q", p.Name, posRest, pos)
+	}
+	if other, ok := specs.positionals[index]; ok {
+		return fmt.Errorf("fields %s and %s are both bound to position %d", typ.Field(other).Name, p.Name, index)
+	}
+	specs.positionals[index] = i
+	return nil
+}
+
+// getFromPositionals binds the positional arguments of c to the fields
+// declaring their position and returns the arguments left unbound. The rest
+// field takes every argument after the highest declared position.
+func (l *loader) getFromPositionals(c *command) ([]string, error) {
+	s := reflect.ValueOf(c.configuration).Elem()
+	positional := c.args.positional
+
+	next := 0
+	for index := range c.specs.positionals {
+		if index >= next {
+			next = index + 1
+		}
+	}
+
+	var left []string
+	for index, value := range positional {
+		if i, ok := c.specs.positionals[index]; ok {
+			if err := setStringToValue(s.Field(i), value); err != nil {
+				p := s.Type().Field(i)
+				return nil, fmt.Errorf("invalid value %q for positional argument %s: %v", mask(p, value), p.Name, maskError(p, value, err))
+			}
+			l.setBy(c.configuration, s.Type().Field(i), SourceArg, "position "+strconv.Itoa(index), value)
+		} else if index < next || c.specs.rest < 0 {
+			left = append(left, value)
+		}
+	}
+
+	if c.specs.rest >= 0 && len(positional) > next {
+		f := s.Field(c.specs.rest)
+		values := reflect.MakeSlice(f.Type(), len(positional)-next, len(positional)-next)
+		for j, value := range positional[next:] {
+			if err := setStringToValue(values.Index(j), value); err != nil {
+				p := s.Type().Field(c.specs.rest)
+				return nil, fmt.Errorf("invalid value %q for positional argument %s: %v", mask(p, value), p.Name, maskError(p, value, err))
+			}
+		}
+		f.Set(values)
+		l.setBy(c.configuration, s.Type().Field(c.specs.rest), SourceArg, "position "+strconv.Itoa(next)+"...", strings.Join(positional[next:], " "))
+	}
+	return left, nil
+}
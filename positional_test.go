@@ -0,0 +1,118 @@
+package gonfig
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type copyConf struct {
+	Force   bool     `short:"f"`
+	Target  string   `pos:"0" description:"where to copy to"`
+	Mode    uint32   `pos:"1"`
+	Sources []string `pos:"rest"`
+}
+
+func Test_Load_should_bind_positional_arguments(t *testing.T) {
+	args := []string{"cp", "/tmp", "-f", "644", "a.txt", "b.txt"}
+
+	conf := copyConf{}
+	report, err := Load(&conf, Options{Args: args})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if conf.Target != "/tmp" {
+		t.Error("Target should be /tmp", conf.Target)
+	}
+	if conf.Mode != 644 {
+		t.Error("Mode should be 644", conf.Mode)
+	}
+	if !conf.Force {
+		t.Error("Force should be true", conf.Force)
+	}
+	if !reflect.DeepEqual(conf.Sources, []string{"a.txt", "b.txt"}) {
+		t.Error("unexpected Sources", conf.Sources)
+	}
+	if len(report.Args) != 0 {
+		t.Error("there should be no positional argument left", report.Args)
+	}
+}
+
+func Test_Load_should_convert_rest_of_positional_arguments(t *testing.T) {
+	type Conf struct {
+		Op      string `pos:"0"`
+		Numbers []int  `pos:"rest"`
+	}
+	args := []string{"calc", "sum", "1", "-2", "3"}
+
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: args})
+
+	if err != nil {
+		t.Error("Load unexpected error occured", err)
+	}
+	if !reflect.DeepEqual(conf.Numbers, []int{1, -2, 3}) {
+		t.Error("unexpected Numbers", conf.Numbers)
+	}
+}
+
+func Test_Load_should_leave_unbound_positional_arguments(t *testing.T) {
+	type Conf struct {
+		Name string `pos:"0"`
+	}
+	args := []string{"cmd", "first", "second"}
+
+	conf := Conf{}
+	report, _ := Load(&conf, Options{Args: args})
+
+	if conf.Name != "first" {
+		t.Error("Name should be first", conf.Name)
+	}
+	if !reflect.DeepEqual(report.Args, []string{"second"}) {
+		t.Error("unexpected positional arguments", report.Args)
+	}
+}
+
+func Test_Load_should_fail_on_invalid_positional_argument(t *testing.T) {
+	args := []string{"cp", "/tmp", "rw"}
+
+	conf := copyConf{}
+	_, err := Load(&conf, Options{Args: args})
+
+	if err == nil || !strings.Contains(err.Error(), `invalid value "rw" for positional argument Mode`) {
+		t.Error("Load should fail on an invalid positional argument", err)
+	}
+}
+
+func Test_getFlagSpecs_should_reject_invalid_positions(t *testing.T) {
+	type NotASlice struct {
+		Rest string `pos:"rest"`
+	}
+	type Duplicate struct {
+		A string `pos:"0"`
+		B string `pos:"0"`
+	}
+	type Negative struct {
+		A string `pos:"-1"`
+	}
+	for _, conf := range []interface{}{NotASlice{}, Duplicate{}, Negative{}} {
+		if _, err := getFlagSpecs(reflect.TypeOf(conf)); err == nil {
+			t.Errorf("getFlagSpecs should reject %T", conf)
+		}
+	}
+}
+
+func Test_printUsage_should_list_positional_arguments(t *testing.T) {
+	specs, _ := getFlagSpecs(reflect.TypeOf(copyConf{}))
+	output := &bytes.Buffer{}
+	printUsage(output, "cp", reflect.TypeOf(copyConf{}), specs)
+
+	if !strings.HasPrefix(output.String(), "Usage: cp [flags] <Target> <Mode> [Sources...]") {
+		t.Error("unexpected usage", output.String())
+	}
+	if !strings.Contains(strings.Join(strings.Fields(output.String()), " "), "Arguments: Target string where to copy to") {
+		t.Error("usage should describe the arguments", output.String())
+	}
+}
@@ -0,0 +1,241 @@
+package gonfig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Origin tells where a value of a field was read from.
+type Origin struct {
+	Source Source
+	// Name is the file, flag, environment variable or position the value
+	// was read from. It is empty for default tags and configuration data.
+	Name string
+	// Line is the line of the file holding the value, 0 if it is unknown.
+	Line int
+	// Value is the value as it was read.
+	Value string
+}
+
+func (o Origin) String() string {
+	where := string(o.Source)
+	if len(o.Name) > 0 {
+		where += " " + o.Name
+	}
+	if o.Line > 0 {
+		where += ":" + strconv.Itoa(o.Line)
+	}
+	return where
+}
+
+// Provenance tells where the final value of a field came from.
+type Provenance struct {
+	// Path is the name of the field, prefixed with the names of the structs
+	// holding it, like "Database.Host".
+	Path string
+	// Value is the final value of the field.
+	Value interface{}
+	// Origin is where the final value was read from. Its Source is empty if
+	// no source set the field.
+	Origin Origin
+	// Overridden lists the values read before the final one, in the order
+	// they were read.
+	Overridden []Origin
+}
+
+func (p Provenance) String() string {
+	s := fmt.Sprintf("%s = %v", p.Path, p.Value)
+	if len(p.Origin.Source) == 0 {
+		return s + " (not set)"
+	}
+	s += " from " + p.Origin.String()
+	for i := len(p.Overridden) - 1; i >= 0; i-- {
+		s += fmt.Sprintf(", overriding %v from %s", p.Overridden[i].Value, p.Overridden[i])
+	}
+	return s
+}
+
+// Lookup returns the provenance of the field with the given path.
+func (r *Report) Lookup(path string) (Provenance, bool) {
+	for _, p := range r.Provenance {
+		if p.Path == path {
+			return p, true
+		}
+	}
+	return Provenance{}, false
+}
+
+// record adds an origin to the field with the given path.
+func (l *loader) record(path string, origin Origin) {
+	l.origins[path] = append(l.origins[path], origin)
+	delete(l.decrypted, path)
+}
+
+// recordRead adds the origin of value, read from a document, to the field
+// with the given path. Encrypted values are marked so that their plaintext
+// is taken literally.
+func (l *loader) recordRead(path string, origin Origin, value interface{}) {
+	l.record(path, origin)
+	if hasEncrypted(value) {
+		l.decrypted[path] = true
+	}
+}
+
+// setBy records that field p of configuration was set from source.
+func (l *loader) setBy(configuration interface{}, p reflect.StructField, source Source, key string, value string) {
+	if source == SourceDefault {
+		key = ""
+	}
+	l.record(l.pathOf(configuration)+p.Name, Origin{Source: source, Name: key, Value: value})
+}
+
+// pathOf returns the path prefix of the fields of configuration, which is
+// the main configuration or the one of a selected subcommand.
+func (l *loader) pathOf(configuration interface{}) string {
+	for _, c := range l.commands {
+		if c.configuration == configuration {
+			return c.path
+		}
+	}
+	return ""
+}
+
+// setByDocument records the fields of typ set by the values of document.
+func (l *loader) setByDocument(prefix string, typ reflect.Type, document map[string]interface{}, source Source, name string, lines *lineFinder, keys ...string) {
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		if len(p.PkgPath) > 0 {
+			continue
+		}
+		elem, isStruct := structType(p.Type)
+		if p.Anonymous && isStruct {
+			l.setByDocument(prefix+p.Name+".", elem, document, source, name, lines, keys...)
+			continue
+		}
+
+		key, value, ok := lookupKey(document, getFileKey(p))
+		if !ok {
+			continue
+		}
+		path := append(keys[:len(keys):len(keys)], key)
+		if nested, isMap := value.(map[string]interface{}); isMap && isStruct && !isSecret(p) {
+			l.setByDocument(prefix+p.Name+".", elem, nested, source, name, lines, path...)
+			continue
+		}
+		l.recordRead(prefix+p.Name, Origin{Source: source, Name: name, Line: lines.find(path), Value: fmt.Sprint(value)}, value)
+	}
+}
+
+// provenance lists the final value and the origins of every field.
+func (l *loader) provenance(configuration interface{}) []Provenance {
+	var list []Provenance
+	walkLeaves("", reflect.ValueOf(configuration).Elem(), func(path string, p reflect.StructField, v reflect.Value) {
+		provenance := Provenance{Path: path, Value: v.Interface()}
+		origins := l.origins[path]
+		if len(origins) > 0 {
+			provenance.Origin = origins[len(origins)-1]
+			provenance.Overridden = origins[:len(origins)-1]
+		}
+		if isSecret(p) || holdsSecret(p.Type, nil) {
+			// the values read from the sources can't be masked in part
+			provenance.Value = redacted
+			if !isSecret(p) {
+				provenance.Value = masked(v)
+			}
+			provenance.Origin.Value = redacted
+			masked := make([]Origin, len(provenance.Overridden))
+			for i, origin := range provenance.Overridden {
+				origin.Value = redacted
+				masked[i] = origin
+			}
+			provenance.Overridden = masked
+		}
+		list = append(list, provenance)
+	})
+	return list
+}
+
+// lineFinder finds the line of a key in YAML or JSON data.
+type lineFinder struct {
+	lines []string
+	json  bool
+}
+
+func newLineFinder(data []byte, format Format) *lineFinder {
+	return &lineFinder{lines: strings.Split(string(data), "\n"), json: format == FormatJSON}
+}
+
+// find returns the line of the value at the path of keys, or 0 if it can't
+// be found. In YAML the key of a nested value must be indented like the
+// first line of the block below its parent.
+func (f *lineFinder) find(keys []string) int {
+	start, parentIndent, line := 0, -1, 0
+
+	for _, key := range keys {
+		line = 0
+		childIndent := -1
+		for i := start; i < len(f.lines); i++ {
+			text := strings.TrimRight(f.lines[i], "\r")
+			trimmed := strings.TrimLeft(text, " \t")
+			if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if f.json {
+				if matchesJSONKey(trimmed, key) {
+					line = i + 1
+					break
+				}
+				continue
+			}
+
+			indent := len(text) - len(trimmed)
+			if indent <= parentIndent {
+				break
+			}
+			if childIndent < 0 {
+				childIndent = indent
+			}
+			if indent == childIndent && matchesYAMLKey(trimmed, key) {
+				line = i + 1
+				parentIndent = indent
+				break
+			}
+		}
+		if line == 0 {
+			return 0
+		}
+		start = line
+	}
+	return line
+}
+
+func matchesYAMLKey(text, key string) bool {
+	for _, quote := range []string{"", `"`, "'"} {
+		quoted := quote + key + quote
+		if len(text) > len(quoted) && strings.EqualFold(text[:len(quoted)], quoted) &&
+			strings.HasPrefix(strings.TrimLeft(text[len(quoted):], " \t"), ":") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesJSONKey(text, key string) bool {
+	quoted := []byte(`"` + strings.ToLower(key) + `"`)
+	lower := bytes.ToLower([]byte(text))
+	for i := bytes.Index(lower, quoted); i >= 0; {
+		rest := bytes.TrimLeft(lower[i+len(quoted):], " \t")
+		if bytes.HasPrefix(rest, []byte(":")) {
+			return true
+		}
+		next := bytes.Index(lower[i+1:], quoted)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return false
+}
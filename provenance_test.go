@@ -0,0 +1,109 @@
+package gonfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type provenanceConf struct {
+	Port     int    `default:"80" env:"PORT"`
+	Password string `secret:"true" env:"PASSWORD"`
+	Database struct {
+		Host string `default:"localhost"`
+		Port int
+	}
+}
+
+func Test_Load_should_report_provenance(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config.yaml": &fstest.MapFile{Data: []byte("# settings\nPort: 8080\nDatabase:\n  Port: 5432\n  Host: db\nPassword: fromFile\n")},
+	}
+	env := MapEnv(map[string]string{"PORT": "8081", "PASSWORD": "fromEnv"})
+
+	conf := provenanceConf{}
+	report, err := Load(&conf, Options{Filename: "config.yaml", FS: fsys, Args: []string{"app"}, LookupEnv: env})
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+
+	port, _ := report.Lookup("Port")
+	if port.Origin != (Origin{Source: SourceEnv, Name: "PORT", Value: "8081"}) {
+		t.Error("Port should come from env PORT", port.Origin)
+	}
+	overridden := []Origin{{Source: SourceDefault, Value: "80"}, {Source: SourceFile, Name: "config.yaml", Line: 2, Value: "8080"}}
+	if !reflect.DeepEqual(port.Overridden, overridden) {
+		t.Error("Port should override the default and the file", port.Overridden)
+	}
+	if port.String() != "Port = 8081 from env PORT, overriding 8080 from file config.yaml:2, overriding 80 from default" {
+		t.Error("unexpected description", port.String())
+	}
+
+	host, _ := report.Lookup("Database.Host")
+	if host.Origin != (Origin{Source: SourceFile, Name: "config.yaml", Line: 5, Value: "db"}) {
+		t.Error("Database.Host should come from line 5 of the file", host.Origin)
+	}
+	dbPort, _ := report.Lookup("Database.Port")
+	if dbPort.Origin.Line != 4 {
+		t.Error("Database.Port should come from line 4 of the file", dbPort.Origin)
+	}
+
+	password, _ := report.Lookup("Password")
+	if password.Value != redacted || password.Origin.Value != redacted || password.Overridden[0].Value != redacted {
+		t.Error("the values of Password should be masked", password)
+	}
+}
+
+func Test_Load_should_report_provenance_of_arguments(t *testing.T) {
+	args := []string{"app", "serve", "--Port", "81"}
+
+	conf := cliConf{}
+	report, err := Load(&conf, Options{Args: args})
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+
+	port, ok := report.Lookup("Serve.Port")
+	if !ok || port.Origin != (Origin{Source: SourceArg, Name: "Port", Value: "81"}) {
+		t.Error("Serve.Port should come from the argument", port)
+	}
+	if debug, _ := report.Lookup("Debug"); len(debug.Origin.Source) != 0 || !strings.HasSuffix(debug.String(), "(not set)") {
+		t.Error("Debug should not be set", debug)
+	}
+}
+
+func Test_Load_should_mask_secrets_in_containers_in_provenance(t *testing.T) {
+	type Conf struct {
+		DBs []struct {
+			Host     string
+			Password string `secret:"true"`
+		}
+	}
+	data := []byte("DBs:\n  - Host: db\n    Password: fromfile\n")
+
+	conf := Conf{}
+	report, err := Load(&conf, Options{Data: data, Args: []string{"app"}})
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+
+	dbs, _ := report.Lookup("DBs")
+	if s := dbs.String(); strings.Contains(s, "fromfile") || !strings.Contains(s, "db") {
+		t.Error("the secrets of DBs should be masked", s)
+	}
+	if dbs.Origin.Value != redacted {
+		t.Error("the value read from the data should be masked", dbs.Origin)
+	}
+}
+
+func Test_lineFinder_should_find_json_keys(t *testing.T) {
+	lines := newLineFinder([]byte("{\n  \"port\": 1,\n  \"database\": {\n    \"port\": 2\n  }\n}"), FormatJSON)
+
+	if line := lines.find([]string{"database", "port"}); line != 4 {
+		t.Error("database.port should be on line 4", line)
+	}
+	if line := lines.find([]string{"missing"}); line != 0 {
+		t.Error("missing keys should not be found", line)
+	}
+}
@@ -0,0 +1,204 @@
+package gonfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GenerateSample returns an example file for configuration in format, with
+// every key set to its default or its zero value. In YAML and TOML each key
+// is preceded by comments holding its description, type and the environment
+// variable and flags setting it. Deprecated and unexported fields are left
+// out, and so are the defaults of secrets.
+func GenerateSample(configuration interface{}, format Format) ([]byte, error) {
+	schema, err := GenerateSchema(configuration)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Sample(format)
+}
+
+// Sample returns an example file for the object described by s, see
+// GenerateSample.
+func (s *Schema) Sample(format Format) ([]byte, error) {
+	var b bytes.Buffer
+	switch format {
+	case FormatYAML, "":
+		writeYAMLSample(&b, s, "")
+	case FormatTOML:
+		writeTOMLSample(&b, s, "")
+	case FormatJSON:
+		data, err := json.MarshalIndent(sampleValue(s), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return b.Bytes(), nil
+}
+
+func writeYAMLSample(b *bytes.Buffer, s *Schema, indent string) {
+	written := 0
+	for _, property := range s.Properties {
+		if property.Schema.Deprecated {
+			continue
+		}
+		if written > 0 && len(indent) == 0 {
+			b.WriteByte('\n')
+		}
+		written++
+		writeComments(b, property.Schema, indent, isRequiredProperty(s, property.Name))
+
+		if property.Schema.Type == "object" && len(property.Schema.Properties) > 0 {
+			fmt.Fprintf(b, "%s%s:\n", indent, property.Name)
+			writeYAMLSample(b, property.Schema, indent+"  ")
+			continue
+		}
+		fmt.Fprintf(b, "%s%s: %s\n", indent, property.Name, formatSampleValue(sampleValue(property.Schema)))
+	}
+}
+
+func writeTOMLSample(b *bytes.Buffer, s *Schema, table string) {
+	// the keys of a table come before its subtables
+	for _, property := range s.Properties {
+		if property.Schema.Deprecated || isTable(property.Schema) {
+			continue
+		}
+		writeComments(b, property.Schema, "", isRequiredProperty(s, property.Name))
+		fmt.Fprintf(b, "%s = %s\n", property.Name, formatSampleValue(sampleValue(property.Schema)))
+	}
+	for _, property := range s.Properties {
+		if property.Schema.Deprecated || !isTable(property.Schema) {
+			continue
+		}
+		name := property.Name
+		if len(table) > 0 {
+			name = table + "." + name
+		}
+		b.WriteByte('\n')
+		writeComments(b, property.Schema, "", isRequiredProperty(s, property.Name))
+		fmt.Fprintf(b, "[%s]\n", name)
+		writeTOMLSample(b, property.Schema, name)
+	}
+}
+
+func isTable(s *Schema) bool {
+	return s.Type == "object" && (len(s.Properties) > 0 || s.AdditionalProperties != nil)
+}
+
+func isRequiredProperty(s *Schema, name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// writeComments writes the description of s and a line with its type and
+// sources, like "# integer, required, env PORT, flag --port or -p".
+func writeComments(b *bytes.Buffer, s *Schema, indent string, required bool) {
+	if len(s.Description) > 0 {
+		for _, line := range strings.Split(s.Description, "\n") {
+			fmt.Fprintf(b, "%s# %s\n", indent, line)
+		}
+	}
+
+	var details []string
+	if len(s.Command) > 0 {
+		details = append(details, "subcommand "+s.Command)
+	} else if typ := describeType(s); len(typ) > 0 {
+		details = append(details, typ)
+	}
+	if required {
+		details = append(details, "required")
+	}
+	if s.Secret {
+		details = append(details, "secret")
+	}
+	if len(s.Env) > 0 {
+		details = append(details, "env "+s.Env)
+	}
+	if len(s.Flag) > 0 {
+		flag := "flag --" + s.Flag
+		if len(s.Short) > 0 {
+			flag += " or -" + s.Short
+		}
+		details = append(details, flag)
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(b, "%s# %s\n", indent, strings.Join(details, ", "))
+	}
+}
+
+func describeType(s *Schema) string {
+	switch {
+	case s.Type == "array" && s.Items != nil && len(s.Items.Type) > 0:
+		return "list of " + s.Items.Type
+	case s.Type == "object" && s.AdditionalProperties != nil && len(s.AdditionalProperties.Type) > 0:
+		return "map of " + s.AdditionalProperties.Type
+	case s.Type == "object":
+		return ""
+	}
+	return s.Type
+}
+
+// sampleValue returns the default of s, or the zero value of its type.
+func sampleValue(s *Schema) interface{} {
+	if s.Default != nil {
+		return s.Default
+	}
+	switch s.Type {
+	case "string":
+		return ""
+	case "boolean":
+		return false
+	case "integer", "number":
+		return 0
+	case "array":
+		return []interface{}{}
+	case "object":
+		values := orderedValues{}
+		for _, property := range s.Properties {
+			if !property.Schema.Deprecated {
+				values = append(values, Property{Name: property.Name, Schema: &Schema{Default: sampleValue(property.Schema)}})
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// orderedValues are the values of an object written as JSON in the order
+// of its properties, each value is the Default of its schema.
+type orderedValues Properties
+
+func (v orderedValues) MarshalJSON() ([]byte, error) {
+	return marshalObject(len(v), func(i int) (string, interface{}) {
+		return v[i].Name, v[i].Schema.Default
+	})
+}
+
+// formatSampleValue writes a scalar, an empty list or an empty map in the
+// syntax shared by YAML and TOML.
+func formatSampleValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		return "[]"
+	case orderedValues:
+		return "{}"
+	case nil:
+		return `""`
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return `""`
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,81 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func Test_GenerateSample_should_write_commented_yaml(t *testing.T) {
+	data, err := GenerateSample(schemaConf{}, FormatYAML)
+	if err != nil {
+		t.Fatal("GenerateSample unexpected error occured", err)
+	}
+
+	expected := `# port to listen on
+# integer, required, env PORT, flag --port or -p
+Port: 8080
+
+# string, env Host, flag --Host
+host: ""
+
+# string, secret, env Password, flag --Password
+Password: ""
+
+# integer, env Workers, flag --Workers
+Workers: 0
+
+# list of string
+Tags: []
+
+# map of integer
+Labels: {}
+
+Database:
+  # string
+  Host: ""
+
+# subcommand serve
+Serve:
+  # integer, env Port, flag --Port or -p
+  Port: 8080
+  # string, env SERVE_ROOT, flag --Root
+  Root: ""
+`
+	if string(data) != expected {
+		t.Error("unexpected sample", string(data))
+	}
+}
+
+func Test_GenerateSample_should_write_readable_files(t *testing.T) {
+	for _, format := range []Format{FormatYAML, FormatJSON} {
+		data, err := GenerateSample(&schemaConf{}, format)
+		if err != nil {
+			t.Fatal("GenerateSample unexpected error occured", err)
+		}
+		conf := schemaConf{}
+		if err := unmarshal(data, format, &conf); err != nil || conf.Port != 8080 || conf.Serve.Port != 8080 {
+			t.Errorf("the %s sample should be readable: %v %+v", format, err, conf)
+		}
+	}
+
+	data, _ := GenerateSample(&schemaConf{}, FormatJSON)
+	if !json.Valid(data) || !strings.HasPrefix(string(data), "{\n  \"Port\": 8080,\n  \"host\": \"\"") {
+		t.Error("unexpected json sample", string(data))
+	}
+}
+
+func Test_GenerateSample_should_write_toml_tables_last(t *testing.T) {
+	data, err := GenerateSample(schemaConf{}, FormatTOML)
+	if err != nil {
+		t.Fatal("GenerateSample unexpected error occured", err)
+	}
+	sample := string(data)
+
+	if !strings.Contains(sample, "Port = 8080\n") || !strings.Contains(sample, "[Database]\n# string\nHost = \"\"\n") {
+		t.Error("unexpected toml sample", sample)
+	}
+	if strings.Index(sample, "Tags = []") > strings.Index(sample, "[Labels]") {
+		t.Error("the keys should come before the tables", sample)
+	}
+}
@@ -0,0 +1,211 @@
+package gonfig
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// schemaDraft is the JSON Schema version GenerateSchema writes.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// tag name to list a field in the required properties of the schema. Load
+// doesn't check it, validating the values is left to the application.
+const requiredTagName = "required"
+
+func isRequired(p reflect.StructField) bool {
+	return p.Tag.Get(requiredTagName) == "true"
+}
+
+// Schema is a JSON Schema describing a configuration file. The x- keywords
+// tell where else gonfig reads a field from, so tools can explain or apply
+// the other sources without the Go type.
+type Schema struct {
+	Schema               string      `json:"$schema,omitempty"`
+	Title                string      `json:"title,omitempty"`
+	Description          string      `json:"description,omitempty"`
+	Type                 string      `json:"type,omitempty"`
+	Default              interface{} `json:"default,omitempty"`
+	Minimum              *float64    `json:"minimum,omitempty"`
+	Properties           Properties  `json:"properties,omitempty"`
+	AdditionalProperties *Schema     `json:"additionalProperties,omitempty"`
+	Items                *Schema     `json:"items,omitempty"`
+	Required             []string    `json:"required,omitempty"`
+	Deprecated           bool        `json:"deprecated,omitempty"`
+
+	// Env and Flag are the environment variable and the long command-line
+	// flag setting the field, Short its short flag.
+	Env   string `json:"x-env,omitempty"`
+	Flag  string `json:"x-flag,omitempty"`
+	Short string `json:"x-short,omitempty"`
+	// Aliases are the deprecated keys, flags and environment variables the
+	// field is also read from.
+	Aliases []string `json:"x-aliases,omitempty"`
+	// Command is the name of the subcommand whose configuration the object is.
+	Command string `json:"x-command,omitempty"`
+	// Secret marks values that must not be shown.
+	Secret bool `json:"x-secret,omitempty"`
+}
+
+// Property is a named property of an object.
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties are the properties of an object in the order of the fields of
+// the struct. They are written as a JSON object keeping that order.
+type Properties []Property
+
+// Get returns the schema of the property named name, or nil.
+func (p Properties) Get(name string) *Schema {
+	for _, property := range p {
+		if property.Name == name {
+			return property.Schema
+		}
+	}
+	return nil
+}
+
+func (p Properties) MarshalJSON() ([]byte, error) {
+	return marshalObject(len(p), func(i int) (string, interface{}) {
+		return p[i].Name, p[i].Schema
+	})
+}
+
+// UnmarshalJSON reads the properties in order. Numbers in them, like
+// defaults, are read as json.Number so large integers keep their precision.
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return fmt.Errorf("properties should be an object")
+	}
+	*p = nil
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		property := Property{Name: token.(string)}
+		if err := decoder.Decode(&property.Schema); err != nil {
+			return err
+		}
+		*p = append(*p, property)
+	}
+	return nil
+}
+
+// GenerateSchema returns the JSON Schema of the files configuration, a struct
+// or a pointer to one, is read from. Fields are described by their type and
+// their default, description, required, deprecated and secret tags. Secret
+// defaults are left out, and so are the defaults of nested structs, which
+// gonfig doesn't read.
+func GenerateSchema(configuration interface{}) (*Schema, error) {
+	typ := reflect.TypeOf(configuration)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("configuration should be a struct or a pointer to a struct type")
+	}
+
+	schema := objectSchema(typ, true)
+	schema.Schema = schemaDraft
+	schema.Title = typ.Name()
+	return schema, nil
+}
+
+// objectSchema describes the struct typ. sources tells if default tags, the
+// environment and the command line are read into its fields, which is only
+// the case for the main configuration and the ones of subcommands.
+func objectSchema(typ reflect.Type, sources bool) *Schema {
+	schema := &Schema{Type: "object"}
+	addProperties(schema, typ, sources)
+	return schema
+}
+
+func addProperties(schema *Schema, typ reflect.Type, sources bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		p := typ.Field(i)
+		key := getFileKey(p)
+		if len(p.PkgPath) > 0 || key == "-" {
+			continue
+		}
+		// the fields of embedded structs are read like fields of typ
+		if elem, ok := structType(p.Type); ok && p.Anonymous {
+			addProperties(schema, elem, false)
+			continue
+		}
+
+		var property *Schema
+		if elem, _ := structType(p.Type); isCommand(p) {
+			property = objectSchema(elem, true)
+			property.Command = p.Tag.Get(cmdTagName)
+		} else {
+			property = typeSchema(p.Type)
+		}
+		property.Description = p.Tag.Get(descriptionTagName)
+		property.Deprecated = isDeprecated(p)
+		property.Secret = isSecret(p)
+		property.Aliases = getAliases(p)
+
+		if sources && !p.Anonymous && isScalar(p.Type) {
+			property.Env = getKey(p, envTagName)
+			if !isPositional(p) {
+				property.Flag = getKey(p, argTagName)
+				property.Short = p.Tag.Get(shortTagName)
+			}
+		}
+		if def := p.Tag.Get(defaultTagName); len(def) > 0 && sources && !property.Secret && isScalar(p.Type) {
+			if v := reflect.New(p.Type).Elem(); setStringToValue(v, def) == nil {
+				property.Default = v.Interface()
+			}
+		}
+		if isRequired(p) {
+			schema.Required = append(schema.Required, key)
+		}
+		schema.Properties = append(schema.Properties, Property{Name: key, Schema: property})
+	}
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// typeSchema describes the values of type typ.
+func typeSchema(typ reflect.Type) *Schema {
+	if typ.Kind() == reflect.Ptr {
+		return typeSchema(typ.Elem())
+	}
+	if typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &Schema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			// encoding/json reads byte slices from base64 strings
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: typeSchema(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(typ.Elem())}
+	case reflect.Struct:
+		if _, ok := structType(typ); ok {
+			return objectSchema(typ, false)
+		}
+	}
+	return &Schema{}
+}
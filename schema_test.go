@@ -0,0 +1,84 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type schemaConf struct {
+	Port     int    `env:"PORT" arg:"port" short:"p" default:"8080" description:"port to listen on" required:"true"`
+	Host     string `json:"host"`
+	Password string `secret:"true" default:"dev"`
+	Old      string `deprecated:"use Host"`
+	Workers  uint16
+	Tags     []string
+	Labels   map[string]int
+	Database struct {
+		Host string `default:"localhost"`
+	}
+	Serve  *serveConf `cmd:"serve"`
+	hidden string
+}
+
+func Test_GenerateSchema_should_describe_fields(t *testing.T) {
+	schema, err := GenerateSchema(&schemaConf{})
+	if err != nil {
+		t.Fatal("GenerateSchema unexpected error occured", err)
+	}
+
+	var names []string
+	for _, property := range schema.Properties {
+		names = append(names, property.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Port", "host", "Password", "Old", "Workers", "Tags", "Labels", "Database", "Serve"}) {
+		t.Error("the properties should follow the fields", names)
+	}
+	if schema.Title != "schemaConf" || !reflect.DeepEqual(schema.Required, []string{"Port"}) {
+		t.Error("unexpected title or required properties", schema.Title, schema.Required)
+	}
+
+	port := schema.Properties.Get("Port")
+	expected := &Schema{Type: "integer", Default: 8080, Description: "port to listen on", Env: "PORT", Flag: "port", Short: "p"}
+	if !reflect.DeepEqual(port, expected) {
+		t.Errorf("unexpected schema of Port: %+v", port)
+	}
+	if password := schema.Properties.Get("Password"); !password.Secret || password.Default != nil {
+		t.Error("the default of secrets should be left out", password)
+	}
+	if !schema.Properties.Get("Old").Deprecated || *schema.Properties.Get("Workers").Minimum != 0 {
+		t.Error("Old should be deprecated and Workers positive")
+	}
+	if tags := schema.Properties.Get("Tags"); tags.Type != "array" || tags.Items.Type != "string" || len(tags.Env) > 0 {
+		t.Error("Tags should be an array of strings not read from the environment", tags)
+	}
+	if labels := schema.Properties.Get("Labels"); labels.AdditionalProperties.Type != "integer" {
+		t.Error("Labels should be a map of integers", labels)
+	}
+	if host := schema.Properties.Get("Database").Properties.Get("Host"); host.Default != nil || len(host.Env) > 0 {
+		t.Error("nested fields should not have defaults or be read from the environment", host)
+	}
+	if serve := schema.Properties.Get("Serve"); serve.Command != "serve" || serve.Properties.Get("Root").Env != "SERVE_ROOT" {
+		t.Error("subcommands should be described with their sources", serve)
+	}
+}
+
+func Test_Schema_should_keep_property_order_in_json(t *testing.T) {
+	schema, _ := GenerateSchema(schemaConf{})
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal("Marshal unexpected error occured", err)
+	}
+	if !strings.Contains(string(data), `"properties":{"Port":{`) || !strings.Contains(string(data), `"x-env":"PORT"`) {
+		t.Error("unexpected json", string(data))
+	}
+
+	var read Schema
+	if err := json.Unmarshal(data, &read); err != nil {
+		t.Fatal("Unmarshal unexpected error occured", err)
+	}
+	if len(read.Properties) != len(schema.Properties) || read.Properties[1].Name != "host" || read.Properties.Get("Database").Properties.Get("Host") == nil {
+		t.Error("the properties should be read in order", read.Properties)
+	}
+}
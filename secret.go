@@ -0,0 +1,86 @@
+package gonfig
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// tag name to mark a field holding a secret that must not be shown
+const secretTagName = "secret"
+
+// redacted replaces the value of a secret wherever it would be shown.
+const redacted = "******"
+
+// Secret is a string that is masked whenever it is printed with the fmt
+// package. Convert it to a string to use its value. Fields of type Secret are
+// treated like fields tagged secret:"true".
+type Secret string
+
+func (s Secret) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+	return redacted
+}
+
+// GoString masks the value for the %#v verb.
+func (s Secret) GoString() string {
+	return `"` + s.String() + `"`
+}
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// isSecret tells if field p is tagged secret:"true" or holds Secret values.
+func isSecret(p reflect.StructField) bool {
+	if p.Tag.Get(secretTagName) == "true" {
+		return true
+	}
+	typ := p.Type
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	return typ == secretType
+}
+
+// holdsSecret tells if values of typ hold secrets, either Secret values or
+// the values of fields tagged secret:"true". seen breaks the recursion of
+// types holding themselves.
+func holdsSecret(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	if typ == secretType {
+		return true
+	}
+	if typ.Kind() != reflect.Struct || seen[typ] {
+		return false
+	}
+	if seen == nil {
+		seen = map[reflect.Type]bool{}
+	}
+	seen[typ] = true
+	for i := 0; i < typ.NumField(); i++ {
+		if p := typ.Field(i); len(p.PkgPath) == 0 && (isSecret(p) || holdsSecret(p.Type, seen)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mask returns value, or the redacted placeholder if p is secret.
+func mask(p reflect.StructField, value string) string {
+	if isSecret(p) && len(value) > 0 {
+		return redacted
+	}
+	return value
+}
+
+// maskError removes value from err if p is secret. The parse errors of
+// strconv quote the value they failed on.
+func maskError(p reflect.StructField, value string, err error) error {
+	if err == nil || !isSecret(p) || len(value) == 0 {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), value, redacted))
+}
@@ -0,0 +1,86 @@
+package gonfig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Secret_should_be_masked_when_printed(t *testing.T) {
+	password := Secret("hunter2")
+
+	for _, format := range []string{"%v", "%s", "%q", "%#v", "%+v"} {
+		if output := fmt.Sprintf(format, struct{ Password Secret }{password}); strings.Contains(output, "hunter2") {
+			t.Errorf("%s should mask the secret: %s", format, output)
+		}
+	}
+	if string(password) != "hunter2" {
+		t.Error("the value should be kept", string(password))
+	}
+}
+
+func Test_Load_should_set_and_mask_Secret_fields(t *testing.T) {
+	type Conf struct {
+		Password Secret `env:"PASSWORD"`
+	}
+	env := MapEnv(map[string]string{"PASSWORD": "hunter2"})
+
+	conf := Conf{}
+	report, err := Load(&conf, Options{Args: []string{"app"}, LookupEnv: env})
+
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+	if conf.Password != "hunter2" {
+		t.Error("Password should be hunter2", string(conf.Password))
+	}
+	if p, _ := report.Lookup("Password"); p.Origin.Value != redacted || strings.Contains(p.String(), "hunter2") {
+		t.Error("the provenance of Password should be masked", p)
+	}
+}
+
+func Test_Load_should_not_leak_secrets_in_errors(t *testing.T) {
+	type Conf struct {
+		Pin int `pos:"0" secret:"true"`
+	}
+
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: []string{"app", "12ab34"}})
+
+	if err == nil {
+		t.Fatal("Load should fail on an invalid pin")
+	}
+	if strings.Contains(err.Error(), "12ab34") {
+		t.Error("the error should not contain the secret", err)
+	}
+}
+
+func Test_printUsage_should_mask_secret_defaults(t *testing.T) {
+	type Conf struct {
+		Token string `default:"dev-token" secret:"true"`
+	}
+	specs, _ := getFlagSpecs(reflect.TypeOf(Conf{}))
+	output := &bytes.Buffer{}
+	printUsage(output, "app", reflect.TypeOf(Conf{}), specs)
+
+	if strings.Contains(output.String(), "dev-token") {
+		t.Error("usage should mask the default of secrets", output.String())
+	}
+}
+
+func Test_isSecret_should_look_through_containers(t *testing.T) {
+	type Conf struct {
+		Tokens   map[string]Secret
+		Keys     [][]*Secret
+		Password Secret
+		Labels   map[string]string
+	}
+	typ := reflect.TypeOf(Conf{})
+	for i, expected := range []bool{true, true, true, false} {
+		if isSecret(typ.Field(i)) != expected {
+			t.Errorf("isSecret of %s should be %v", typ.Field(i).Name, expected)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build !plan9
+
+package gonfig
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Reloader reloads a configuration, keeping the current one if it fails.
+// Watcher and Store implement it.
+type Reloader interface {
+	Reload() error
+}
+
+// ReloadOnSignal reloads r whenever one of signals is received, SIGHUP if
+// none are given, and passes the result of every reload to fn. An invalid
+// configuration is never applied. The returned function stops listening.
+func ReloadOnSignal(r Reloader, fn func(error), signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, signals...)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			case <-c:
+				err := r.Reload()
+				if fn != nil {
+					fn(err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,72 @@
+//go:build !windows && !plan9
+
+package gonfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type countingReloader struct {
+	reloads int
+	err     error
+}
+
+func (r *countingReloader) Reload() error {
+	r.reloads++
+	return r.err
+}
+
+func Test_ReloadOnSignal_should_reload_on_signal(t *testing.T) {
+	r := &countingReloader{err: errors.New("invalid")}
+	results := make(chan error, 1)
+	stop := ReloadOnSignal(r, func(err error) { results <- err }, syscall.SIGUSR1)
+	defer stop()
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case err := <-results:
+		if err != r.err {
+			t.Error("the result of the reload should be reported", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the configuration should be reloaded")
+	}
+	if r.reloads != 1 {
+		t.Error("there should be one reload", r.reloads)
+	}
+}
+
+func Test_ReloadOnSignal_should_keep_configuration_if_invalid(t *testing.T) {
+	filename := tmpFileWithContent("Port: 80", t)
+	defer os.Remove(filename)
+
+	conf := &watchConf{}
+	w, err := Watch(conf, watchOptions(filename), time.Hour)
+	if err != nil {
+		t.Fatal("Watch unexpected error occured", err)
+	}
+	defer w.Close()
+
+	results := make(chan error, 1)
+	stop := ReloadOnSignal(w, func(err error) { results <- err }, syscall.SIGUSR1)
+	defer stop()
+
+	ioutil.WriteFile(filename, []byte("Port: -1"), 0644)
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case err := <-results:
+		if err == nil {
+			t.Error("the invalid configuration should be reported")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the configuration should be reloaded")
+	}
+	if w.Current() != conf {
+		t.Error("the invalid configuration should not be applied", w.Current())
+	}
+}
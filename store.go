@@ -0,0 +1,77 @@
+package gonfig
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Store holds a configuration of type T that can be replaced while other
+// goroutines read it. Get returns a snapshot that is never modified, an
+// update stores a new value instead.
+type Store[T any] struct {
+	opts    Options
+	current atomic.Pointer[T]
+	// mu serializes updates so a reload can't overwrite a newer value
+	mu sync.Mutex
+}
+
+// NewStore loads a configuration of type T with opts and returns a store
+// holding it.
+func NewStore[T any](opts Options) (*Store[T], error) {
+	conf := new(T)
+	if _, err := Load(conf, opts); err != nil {
+		return nil, err
+	}
+	s := &Store[T]{opts: opts}
+	s.current.Store(conf)
+	return s, nil
+}
+
+// Get returns the current configuration. It must not be modified.
+func (s *Store[T]) Get() *T {
+	return s.current.Load()
+}
+
+// Update validates conf and makes it the current configuration.
+func (s *Store[T]) Update(conf *T) error {
+	if validator, ok := interface{}(conf).(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current.Store(conf)
+	return nil
+}
+
+// Reload loads a new configuration with the options of the store and makes
+// it the current one. The current configuration is kept if it fails.
+func (s *Store[T]) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	opts := s.opts
+	opts.RequireFile = true
+
+	conf := new(T)
+	if _, err := Load(conf, opts); err != nil {
+		return err
+	}
+	s.current.Store(conf)
+	return nil
+}
+
+// Watch keeps the store up to date with the file of its configuration, like
+// the Watch function does.
+func (s *Store[T]) Watch(interval time.Duration) *Watcher {
+	w := newWatcher(s.Get(), s.opts, interval)
+	w.Subscribe(func(old, new interface{}) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.current.Store(new.(*T))
+	})
+	go w.watch()
+	return w
+}
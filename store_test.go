@@ -0,0 +1,119 @@
+package gonfig
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_NewStore_should_load_configuration(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent("Port: 80", t)
+	defer os.Remove(filename)
+
+	store, err := NewStore[watchConf](watchOptions(filename))
+	if err != nil {
+		t.Fatal("NewStore unexpected error occured", err)
+	}
+	if conf := store.Get(); conf.Port != 80 || conf.Level != "info" {
+		t.Error("unexpected configuration", conf)
+	}
+}
+
+func Test_Store_Update_should_validate(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewStore[watchConf](watchOptions(""))
+	if err != nil {
+		t.Fatal("NewStore unexpected error occured", err)
+	}
+	current := store.Get()
+
+	if err := store.Update(&watchConf{Port: -1}); err == nil {
+		t.Error("Update should fail on an invalid configuration")
+	}
+	if store.Get() != current {
+		t.Error("the configuration should not be replaced", store.Get())
+	}
+
+	next := &watchConf{Port: 8080}
+	if err := store.Update(next); err != nil {
+		t.Error("Update unexpected error occured", err)
+	}
+	if store.Get() != next {
+		t.Error("the configuration should be replaced", store.Get())
+	}
+}
+
+func Test_Store_Reload_should_not_modify_snapshots(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent("Port: 80", t)
+	defer os.Remove(filename)
+
+	store, err := NewStore[watchConf](watchOptions(filename))
+	if err != nil {
+		t.Fatal("NewStore unexpected error occured", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					if port := store.Get().Port; port != 80 && port != 81 {
+						t.Error("unexpected Port", port)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	snapshot := store.Get()
+	ioutil.WriteFile(filename, []byte("Port: 81"), 0644)
+	if err := store.Reload(); err != nil {
+		t.Error("Reload unexpected error occured", err)
+	}
+	close(stop)
+	wg.Wait()
+
+	if snapshot.Port != 80 {
+		t.Error("the snapshot should not be modified", snapshot.Port)
+	}
+	if store.Get().Port != 81 {
+		t.Error("Port should be 81", store.Get().Port)
+	}
+}
+
+func Test_Store_Watch_should_update_store(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent("Port: 80", t)
+	defer os.Remove(filename)
+
+	store, err := NewStore[watchConf](watchOptions(filename))
+	if err != nil {
+		t.Fatal("NewStore unexpected error occured", err)
+	}
+	w := store.Watch(10 * time.Millisecond)
+	defer w.Close()
+
+	ioutil.WriteFile(filename, []byte("Port: 81"), 0644)
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Get().Port != 81 {
+		if time.Now().After(deadline) {
+			t.Fatal("the store should be updated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,159 @@
+package gonfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultHTTPClient fetches configuration URLs if Options.HTTPClient is nil.
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// suffix of the file next to Options.CacheFile holding the ETag of the copy
+const etagSuffix = ".etag"
+
+func isURL(filename string) bool {
+	lower := strings.ToLower(filename)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "file://")
+}
+
+// redactURL returns filename with the password of a URL masked, for logs,
+// errors and the report.
+func redactURL(filename string) string {
+	if isURL(filename) {
+		if u, err := url.Parse(filename); err == nil {
+			return u.Redacted()
+		}
+	}
+	return filename
+}
+
+// readFile returns the content of filename, a path in opts.FS or a URL.
+func (l *loader) readFile(filename string) ([]byte, error) {
+	if isURL(filename) {
+		return l.readURL(filename)
+	}
+	return fs.ReadFile(l.opts.FS, filename)
+}
+
+// readURL returns the content of a file:// URL, read from opts.FS, or
+// fetches an http:// or https:// URL.
+func (l *loader) readURL(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(u.Scheme, "file") {
+		return fs.ReadFile(l.opts.FS, u.Path)
+	}
+	return l.fetch(u)
+}
+
+// fetch downloads u and keeps a copy in opts.CacheFile. The copy is
+// returned if the server reports it unchanged, or if u can't be fetched.
+func (l *loader) fetch(u *url.URL) ([]byte, error) {
+	var cached []byte
+	var etag string
+	if len(l.opts.CacheFile) > 0 {
+		if data, err := os.ReadFile(l.opts.CacheFile); err == nil && l.verifyChecksum(data) == nil {
+			cached = data
+			if tag, err := os.ReadFile(l.opts.CacheFile + etagSuffix); err == nil {
+				etag = strings.TrimSpace(string(tag))
+			}
+		}
+	}
+
+	data, tag, err := l.download(u, etag, cached != nil)
+	if err != nil {
+		if cached != nil {
+			l.logger.Printf("Could not fetch %s, reading cached copy %s : %v", u.Redacted(), l.opts.CacheFile, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	if data == nil {
+		return cached, nil
+	}
+	if err := l.verifyChecksum(data); err != nil {
+		return nil, err
+	}
+
+	if len(l.opts.CacheFile) > 0 {
+		if err := ioutil.WriteFile(l.opts.CacheFile, data, 0600); err != nil {
+			l.logger.Printf("Could not write cached copy %s : %v", l.opts.CacheFile, err)
+		} else if err := ioutil.WriteFile(l.opts.CacheFile+etagSuffix, []byte(tag), 0600); err != nil {
+			l.logger.Printf("Could not write cached copy %s : %v", l.opts.CacheFile, err)
+		}
+	}
+	return data, nil
+}
+
+// download fetches u, revalidating the cached copy with etag. It returns
+// nil data if the cached copy is still current.
+func (l *loader) download(u *url.URL, etag string, hasCache bool) ([]byte, string, error) {
+	client := l.opts.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(etag) > 0 {
+		request.Header.Set("If-None-Match", etag)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotModified && hasCache:
+		return nil, etag, nil
+	case response.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("server responded %s", response.Status)
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, response.Header.Get("ETag"), nil
+}
+
+// verifyChecksum checks data against opts.Checksum if it is set.
+func (l *loader) verifyChecksum(data []byte) error {
+	if len(l.opts.Checksum) == 0 {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, l.opts.Checksum) {
+		return &checksumError{expected: l.opts.Checksum, actual: actual}
+	}
+	return nil
+}
+
+// checksumError is returned for a configuration that doesn't match
+// Options.Checksum. Like a decryptError, it makes Load fail even if the file
+// isn't required, as it may have been tampered with.
+type checksumError struct {
+	expected string
+	actual   string
+}
+
+func (e *checksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch, expected %s but got %s", e.expected, e.actual)
+}
+
+func isChecksumError(err error) bool {
+	_, ok := err.(*checksumError)
+	return ok
+}
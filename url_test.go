@@ -0,0 +1,120 @@
+package gonfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+type urlConf struct {
+	Port int
+	Host string
+}
+
+func Test_Load_should_fetch_and_cache_url(t *testing.T) {
+	var requests, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"Port": 8080, "Host": "edge"}`))
+	}))
+	cache := filepath.Join(t.TempDir(), "config.cache")
+	opts := Options{Filename: server.URL + "/config.json?node=1", CacheFile: cache, RequireFile: true, Args: []string{"app"}}
+
+	for i := 0; i < 2; i++ {
+		conf := urlConf{}
+		if _, err := Load(&conf, opts); err != nil {
+			t.Fatal("Load unexpected error occured", err)
+		}
+		if conf.Port != 8080 || conf.Host != "edge" {
+			t.Error("the configuration should be read from the url", conf)
+		}
+	}
+	if requests != 2 || notModified != 1 {
+		t.Error("the second load should revalidate the cached copy", requests, notModified)
+	}
+
+	server.Close()
+	conf := urlConf{}
+	if _, err := Load(&conf, opts); err != nil || conf.Port != 8080 {
+		t.Error("the cached copy should be read while the server is down", conf, err)
+	}
+}
+
+func Test_Load_should_verify_checksum(t *testing.T) {
+	data := []byte("Port: 8080")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+	sum := sha256.Sum256(data)
+
+	conf := urlConf{}
+	if _, err := Load(&conf, Options{Filename: server.URL, Checksum: hex.EncodeToString(sum[:]), RequireFile: true, Args: []string{"app"}}); err != nil || conf.Port != 8080 {
+		t.Error("a matching checksum should be accepted", conf, err)
+	}
+
+	cache := filepath.Join(t.TempDir(), "config.cache")
+	conf = urlConf{}
+	_, err := Load(&conf, Options{Filename: server.URL, Checksum: "00", CacheFile: cache, RequireFile: true, Args: []string{"app"}})
+	if err == nil || conf.Port != 0 {
+		t.Error("a checksum mismatch should fail", conf, err)
+	}
+	if _, err := os.Stat(cache); !os.IsNotExist(err) {
+		t.Error("a mismatching file should not be cached", err)
+	}
+}
+
+func Test_Load_should_fail_on_checksum_mismatch_of_optional_file(t *testing.T) {
+	fsys := fstest.MapFS{"config.yaml": &fstest.MapFile{Data: []byte("Port: 8080")}}
+
+	conf := urlConf{}
+	_, err := Load(&conf, Options{Filename: "config.yaml", FS: fsys, Checksum: "00", Args: []string{"app"}, Logger: &recordingLogger{}})
+
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Error("a checksum mismatch should fail even if the file isn't required", err)
+	}
+}
+
+func Test_Load_should_not_reveal_url_passwords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	filename := strings.Replace(server.URL, "://", "://admin:s3cret@", 1)
+
+	logger := &recordingLogger{}
+	conf := urlConf{}
+	_, err := Load(&conf, Options{Filename: filename, RequireFile: true, Args: []string{"app"}, Logger: logger})
+
+	if err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Error("the error should not contain the password", err)
+	}
+	if len(logger.lines) == 0 || strings.Contains(strings.Join(logger.lines, "\n"), "s3cret") {
+		t.Error("the logs should not contain the password", logger.lines)
+	}
+}
+
+func Test_Load_should_read_file_url(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(filename, []byte("Port: 8080"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := urlConf{}
+	if _, err := Load(&conf, Options{Filename: "file://" + filepath.ToSlash(filename), RequireFile: true, Args: []string{"app"}}); err != nil || conf.Port != 8080 {
+		t.Error("the configuration should be read from the file url", conf, err)
+	}
+}
@@ -0,0 +1,49 @@
+package gonfig
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+)
+
+// suffix of the environment variable naming a file holding the value of
+// another one, like DB_PASSWORD_FILE for DB_PASSWORD
+const fileEnvSuffix = "_FILE"
+
+// tag name to mark a string field holding the name of a file whose content
+// replaces it once all sources are read
+const fileTagName = "file"
+
+func isFile(p reflect.StructField) bool {
+	return p.Tag.Get(fileTagName) == "true" && p.Type.Kind() == reflect.String
+}
+
+// readValueFile returns the content of the file name without the line break
+// most editors and echo leave at its end.
+func (l *loader) readValueFile(name string) (string, error) {
+	data, err := fs.ReadFile(l.opts.ValueFS, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// inlineFiles replaces the value of every field tagged file:"true" by the
+// content of the file it names. Empty fields are left alone.
+func (l *loader) inlineFiles(configuration interface{}) error {
+	var err error
+	walkLeaves("", reflect.ValueOf(configuration).Elem(), func(path string, p reflect.StructField, v reflect.Value) {
+		if err != nil || !isFile(p) || len(v.String()) == 0 {
+			return
+		}
+		filename := v.String()
+		value, readErr := l.readValueFile(filename)
+		if readErr != nil {
+			err = fmt.Errorf("could not read file %s of field %s: %v", filename, path, readErr)
+			return
+		}
+		v.SetString(value)
+	})
+	return err
+}
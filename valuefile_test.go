@@ -0,0 +1,97 @@
+package gonfig
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func Test_Load_should_read_env_value_from_file(t *testing.T) {
+	type Conf struct {
+		Password Secret `env:"DB_PASSWORD"`
+		User     string `env:"DB_USER"`
+	}
+	fsys := fstest.MapFS{
+		"run/secrets/db":   &fstest.MapFile{Data: []byte("hunter2\n")},
+		"run/secrets/user": &fstest.MapFile{Data: []byte("ignored")},
+	}
+	env := MapEnv(map[string]string{
+		"DB_PASSWORD_FILE": "run/secrets/db",
+		"DB_USER":          "admin",
+		"DB_USER_FILE":     "run/secrets/user",
+	})
+
+	conf := Conf{}
+	report, err := Load(&conf, Options{Args: []string{"app"}, LookupEnv: env, ValueFS: fsys})
+
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+	if conf.Password != "hunter2" {
+		t.Error("Password should be read from the file", string(conf.Password))
+	}
+	if p, _ := report.Lookup("Password"); p.Origin.Name != "DB_PASSWORD_FILE" {
+		t.Error("Password should come from DB_PASSWORD_FILE", p)
+	}
+	if conf.User != "admin" {
+		t.Error("the variable should win over its file", conf.User)
+	}
+}
+
+func Test_Load_should_fail_on_unreadable_env_file(t *testing.T) {
+	type Conf struct {
+		Password string `env:"DB_PASSWORD"`
+	}
+	env := MapEnv(map[string]string{"DB_PASSWORD_FILE": "missing"})
+
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: []string{"app"}, LookupEnv: env, ValueFS: fstest.MapFS{}})
+
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Error("Load should fail on an unreadable file", err)
+	}
+}
+
+func Test_Load_should_inline_file_fields(t *testing.T) {
+	type Conf struct {
+		Certificate string `file:"true" default:"certs/default.pem"`
+		Key         string `file:"true"`
+		TLS         struct {
+			CA string `file:"true"`
+		}
+	}
+	fsys := fstest.MapFS{
+		"certs/default.pem": &fstest.MapFile{Data: []byte("-----BEGIN CERTIFICATE-----\n")},
+		"certs/ca.pem":      &fstest.MapFile{Data: []byte("ca")},
+	}
+	data := []byte("TLS:\n  CA: certs/ca.pem")
+
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: []string{"app"}, Data: data, ValueFS: fsys})
+
+	if err != nil {
+		t.Fatal("Load unexpected error occured", err)
+	}
+	if conf.Certificate != "-----BEGIN CERTIFICATE-----" {
+		t.Error("Certificate should hold the content of the file", conf.Certificate)
+	}
+	if conf.Key != "" {
+		t.Error("empty fields should be left alone", conf.Key)
+	}
+	if conf.TLS.CA != "ca" {
+		t.Error("TLS.CA should hold the content of the file", conf.TLS.CA)
+	}
+}
+
+func Test_Load_should_fail_on_missing_file_field(t *testing.T) {
+	type Conf struct {
+		Certificate string `file:"true" default:"missing.pem"`
+	}
+
+	conf := Conf{}
+	_, err := Load(&conf, Options{Args: []string{"app"}, ValueFS: fstest.MapFS{}})
+
+	if err == nil || !strings.Contains(err.Error(), "Certificate") {
+		t.Error("Load should fail on a missing file", err)
+	}
+}
@@ -0,0 +1,193 @@
+package gonfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/fs"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWatchInterval is how often Watch checks the file for changes if no
+// interval is given.
+const DefaultWatchInterval = time.Second
+
+// Watcher keeps a configuration up to date with its file. Every reload runs
+// the whole default, file, argument and environment pipeline into a new value,
+// so a value returned by Current is never modified afterwards.
+type Watcher struct {
+	opts     Options
+	typ      reflect.Type
+	interval time.Duration
+	logger   Logger
+	current  atomic.Value
+
+	// reloading serializes reloads, mu guards the callbacks and the file
+	// checksum. Neither is held while the callbacks run so they can use the
+	// watcher.
+	reloading   sync.Mutex
+	mu          sync.Mutex
+	checksum    []byte
+	subscribers []func(old, new interface{})
+	onError     func(error)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Watch loads configuration like Load and reloads it whenever the file named
+// by opts.Filename changes. The file is polled every interval. A reload that
+// fails to read the file, to load or to validate the configuration is
+// reported to the OnError callback and leaves the current value in place.
+func Watch(configuration interface{}, opts Options, interval time.Duration) (*Watcher, error) {
+	if _, err := Load(configuration, opts); err != nil {
+		return nil, err
+	}
+	w := newWatcher(configuration, opts, interval)
+	go w.watch()
+	return w, nil
+}
+
+// newWatcher returns a watcher for a configuration loaded with opts, it
+// watches the file once watch is started.
+func newWatcher(configuration interface{}, opts Options, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	if opts.FS == nil {
+		opts.FS = osFS{}
+	}
+	opts.RequireFile = true
+
+	w := &Watcher{
+		opts:     opts,
+		typ:      reflect.TypeOf(configuration).Elem(),
+		interval: interval,
+		logger:   newLoader(opts).logger,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	w.current.Store(configuration)
+	w.checksum, _ = w.readChecksum()
+	return w
+}
+
+// Current returns the pointer to the configuration loaded last.
+func (w *Watcher) Current() interface{} {
+	return w.current.Load()
+}
+
+// Subscribe registers fn to be called with the previous and the new
+// configuration after every successful reload.
+func (w *Watcher) Subscribe(fn func(old, new interface{})) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// OnError registers fn to be called with the error of every failed reload
+// triggered by a change of the file. The error is logged if none is set.
+func (w *Watcher) OnError(fn func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = fn
+}
+
+// Reload loads the configuration into a new value and swaps it in if it is
+// valid. The current value is kept if an error is returned.
+func (w *Watcher) Reload() error {
+	old, configuration, err := w.reload()
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	subscribers := append([]func(old, new interface{}){}, w.subscribers...)
+	w.mu.Unlock()
+	for _, fn := range subscribers {
+		fn(old, configuration)
+	}
+	return nil
+}
+
+// Close stops watching the file.
+func (w *Watcher) Close() {
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+	<-w.done
+}
+
+// reload swaps a newly loaded configuration in and returns the previous
+// and the new value.
+func (w *Watcher) reload() (old, configuration interface{}, err error) {
+	w.reloading.Lock()
+	defer w.reloading.Unlock()
+
+	configuration = reflect.New(w.typ).Interface()
+	if _, err := Load(configuration, w.opts); err != nil {
+		return nil, nil, err
+	}
+	old = w.current.Load()
+	w.current.Store(configuration)
+	return old, configuration, nil
+}
+
+func (w *Watcher) watch() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check reloads the configuration if the checksum of the file changed.
+// A file that can't be read, for example while it is replaced, is checked
+// again on the next tick.
+func (w *Watcher) check() {
+	checksum, err := w.readChecksum()
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	changed := !bytes.Equal(checksum, w.checksum)
+	w.checksum = checksum
+	onError := w.onError
+	w.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	if err := w.Reload(); err != nil {
+		if onError != nil {
+			onError(err)
+		} else {
+			w.logger.Printf("Could not reload configuration from %s : %v", redactURL(w.opts.Filename), err)
+		}
+	}
+}
+
+func (w *Watcher) readChecksum() ([]byte, error) {
+	if len(w.opts.Filename) == 0 {
+		return nil, fs.ErrNotExist
+	}
+	data, err := newLoader(w.opts).readFile(w.opts.Filename)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
@@ -0,0 +1,168 @@
+package gonfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+type watchConf struct {
+	Level string `default:"info"`
+	Port  int
+}
+
+func (c *watchConf) Validate() error {
+	if c.Port < 0 {
+		return errors.New("Port should not be negative")
+	}
+	return nil
+}
+
+func watchOptions(filename string) Options {
+	return Options{Filename: filename, Args: []string{"cmd"}, LookupEnv: MapEnv(nil), Logger: &recordingLogger{}}
+}
+
+func Test_Watcher_Reload_should_swap_configuration(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent("Port: 80", t)
+	defer os.Remove(filename)
+
+	conf := &watchConf{}
+	w, err := Watch(conf, watchOptions(filename), time.Hour)
+	if err != nil {
+		t.Fatal("Watch unexpected error occured", err)
+	}
+	defer w.Close()
+
+	var old, new interface{}
+	w.Subscribe(func(o, n interface{}) { old, new = o, n })
+
+	ioutil.WriteFile(filename, []byte("Port: 81\nLevel: debug"), 0644)
+	if err := w.Reload(); err != nil {
+		t.Fatal("Reload unexpected error occured", err)
+	}
+
+	current := w.Current().(*watchConf)
+	if current.Port != 81 || current.Level != "debug" {
+		t.Error("unexpected configuration after reload", current)
+	}
+	if old != conf || new != current {
+		t.Error("subscribers should get the old and new configuration", old, new)
+	}
+	if conf.Port != 80 {
+		t.Error("the previous configuration should not be modified", conf.Port)
+	}
+}
+
+func Test_Watcher_Reload_should_let_subscribers_use_the_watcher(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent("Port: 80", t)
+	defer os.Remove(filename)
+
+	w, err := Watch(&watchConf{}, watchOptions(filename), time.Hour)
+	if err != nil {
+		t.Fatal("Watch unexpected error occured", err)
+	}
+	defer w.Close()
+
+	subscribed := false
+	w.Subscribe(func(o, n interface{}) {
+		if !subscribed {
+			subscribed = true
+			w.Subscribe(func(o, n interface{}) {})
+			w.Reload()
+		}
+	})
+
+	done := make(chan error)
+	go func() { done <- w.Reload() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error("Reload unexpected error occured", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reload should not block when a subscriber uses the watcher")
+	}
+}
+
+func Test_Watcher_Reload_should_keep_configuration_if_invalid(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent("Port: 80", t)
+	defer os.Remove(filename)
+
+	conf := &watchConf{}
+	w, err := Watch(conf, watchOptions(filename), time.Hour)
+	if err != nil {
+		t.Fatal("Watch unexpected error occured", err)
+	}
+	defer w.Close()
+
+	ioutil.WriteFile(filename, []byte("Port: -1"), 0644)
+	if err := w.Reload(); err == nil {
+		t.Error("Reload should fail on an invalid configuration")
+	}
+	ioutil.WriteFile(filename, []byte("Port: abc"), 0644)
+	if err := w.Reload(); err == nil {
+		t.Error("Reload should fail on an unreadable file")
+	}
+	os.Remove(filename)
+	if err := w.Reload(); err == nil {
+		t.Error("Reload should fail on a missing file")
+	}
+
+	if w.Current() != conf {
+		t.Error("the configuration should not be swapped", w.Current())
+	}
+}
+
+func Test_Watch_should_reload_on_file_change(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent("Port: 80", t)
+	defer os.Remove(filename)
+
+	w, err := Watch(&watchConf{}, watchOptions(filename), 10*time.Millisecond)
+	if err != nil {
+		t.Fatal("Watch unexpected error occured", err)
+	}
+	defer w.Close()
+
+	reloaded := make(chan *watchConf, 1)
+	w.Subscribe(func(old, new interface{}) { reloaded <- new.(*watchConf) })
+	errs := make(chan error, 1)
+	w.OnError(func(err error) { errs <- err })
+
+	ioutil.WriteFile(filename, []byte("Port: -1"), 0644)
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the invalid configuration should be reported")
+	}
+
+	ioutil.WriteFile(filename, []byte("Port: 8080"), 0644)
+	select {
+	case conf := <-reloaded:
+		if conf.Port != 8080 {
+			t.Error("Port should be 8080", conf.Port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the configuration should be reloaded")
+	}
+}
+
+func Test_Watch_should_fail_on_invalid_configuration(t *testing.T) {
+	t.Parallel()
+
+	filename := tmpFileWithContent("Port: -1", t)
+	defer os.Remove(filename)
+
+	if _, err := Watch(&watchConf{}, watchOptions(filename), time.Hour); err == nil {
+		t.Error("Watch should fail on an invalid configuration")
+	}
+}